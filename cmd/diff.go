@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+// runDiff prints the semantic differences between two documents, it exits with
+// 1 when they differ like diff(1).
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	parse := addParseFlags(fs)
+	asJSON := fs.Bool("json", false, "print the changes as a json array")
+	asPatch := fs.Bool("patch", false, "print a JSON Patch (RFC 6902) that turns <original> into <modified>")
+	unordered := fs.Bool("unordered", false, "compare arrays as sets, ignoring the order of their elements")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: json-parser diff [-json | -patch] [-unordered] <original> <modified>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	parseOpts := parse.options()
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	a, err := parseFile(fs.Arg(0), parseOpts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	b, err := parseFile(fs.Arg(1), parseOpts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if *asPatch {
+		ops := internal.CreatePatch(a, b)
+		writeOutput("", internal.MarshalIndent(internal.PatchNode(ops), "", "  "))
+		if len(ops) > 0 {
+			return 1
+		}
+		return 0
+	}
+
+	changes := internal.Diff(a, b, internal.DiffOptions{UnorderedArrays: *unordered})
+	if *asJSON {
+		report := internal.NewArray()
+		for _, c := range changes {
+			report.Elements = append(report.Elements, c.Node())
+		}
+		writeOutput("", internal.MarshalIndent(report, "", "  "))
+	} else {
+		for _, c := range changes {
+			fmt.Println(c)
+		}
+	}
+
+	if len(changes) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+// runFmt reformats the files, like gofmt it prints the result unless -w is
+// given. The result has no comments and writes Infinity and NaN as null, so
+// -w refuses files that have them.
+func runFmt(args []string) int {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	parse := addParseFlags(fs)
+	indent := fs.Int("indent", 2, "spaces per indentation level")
+	tabs := fs.Bool("tabs", false, "indent with tabs instead of spaces")
+	sortKeys := fs.Bool("sort", false, "sort object members by name")
+	arrayWidth := fs.Int("array-width", 0, "put arrays of scalars that fit in this many bytes on one line")
+	newline := fs.Bool("newline", true, "end the output with a newline")
+	write := fs.Bool("w", false, "write the result back to the file instead of stdout, files with comments, Infinity or NaN are refused")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: json-parser fmt [flags] [file ...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	parseOpts := parse.options()
+	if *indent < 0 {
+		fmt.Fprintln(os.Stderr, "-indent can't be negative")
+		return 2
+	}
+
+	opts := internal.FormatOptions{
+		ParseOptions: parseOpts,
+		Indent:       *indent,
+		Tabs:         *tabs,
+		SortKeys:     *sortKeys,
+		ArrayWidth:   *arrayWidth,
+		FinalNewline: *newline,
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = append(files, "-")
+	}
+
+	code := 0
+	for _, file := range files {
+		if *write && file == "-" {
+			fmt.Fprintln(os.Stderr, "can't use -w with stdin")
+			return 2
+		}
+
+		data, err := readInput(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+			continue
+		}
+
+		lexer := internal.NewLexerWithOptions(data, parseOpts)
+		err = lexer.ValidateTokens()
+		var out []byte
+		if err == nil {
+			out, err = internal.Format(lexer.Tokens, opts)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: Not valid json, details: %v\n", file, err)
+			code = 1
+			continue
+		}
+		if *write && refuseWrite(file, lexer.Tokens) {
+			code = 1
+			continue
+		}
+
+		if !*write {
+			os.Stdout.Write(out)
+			continue
+		}
+		if string(out) == data {
+			continue
+		}
+		if err := os.WriteFile(file, out, 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+		}
+	}
+	return code
+}
+
+// refuseWrite prints an error and returns true when writing the tokens back
+// to the file as JSON would lose something: comments are left out and the
+// JSON5 numbers Infinity and NaN become null.
+func refuseWrite(file string, tokens []internal.Token) bool {
+	lost := ""
+	switch {
+	case slices.ContainsFunc(tokens, isComment):
+		lost = "comments"
+	case slices.ContainsFunc(tokens, isNonFinite):
+		lost = "Infinity or NaN"
+	default:
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "%s: can't write back a file with %s, they would be lost\n", file, lost)
+	return true
+}
+
+// refuseWriteData is refuseWrite for data that was already parsed.
+func refuseWriteData(file, data string, opts internal.ParseOptions) bool {
+	lexer := internal.NewLexerWithOptions(data, opts)
+	lexer.ValidateTokens()
+	return refuseWrite(file, lexer.Tokens)
+}
+
+func isComment(t internal.Token) bool {
+	return t.Type == internal.Comment
+}
+
+func isNonFinite(t internal.Token) bool {
+	return t.Type == internal.Number && (t.Literal == "Infinity" || t.Literal == "-Infinity" || t.Literal == "NaN")
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+// runGenGo prints Go type definitions that every document in the files
+// decodes into.
+func runGenGo(args []string) int {
+	fs := flag.NewFlagSet("gen-go", flag.ExitOnError)
+	parse := addParseFlags(fs)
+	ndjson := fs.Bool("ndjson", false, "read one document per line")
+	name := fs.String("type", "Root", "name of the top level type")
+	pkg := fs.String("package", "main", "package clause of the generated file")
+	output := fs.String("o", "", "write the code to this file, - is stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: json-parser gen-go [-ndjson] [-type name] [-package name] [-o file] [file ...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	parseOpts := parse.options()
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = append(files, "-")
+	}
+
+	samples, err := readSamples(files, *ndjson, parseOpts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	src, err := internal.GenerateGo(samples, internal.GoOptions{Package: *pkg, Name: *name})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *output == "" || *output == "-" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = os.WriteFile(*output, src, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
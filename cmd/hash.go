@@ -0,0 +1,54 @@
+package main
+
+import (
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+// runHash prints the SHA-256 of the RFC 8785 canonical form of every file in
+// the format of sha256sum, so equal documents get equal digests no matter
+// how they are written.
+func runHash(args []string) int {
+	fs := flag.NewFlagSet("hash", flag.ExitOnError)
+	parse := addParseFlags(fs)
+	canonical := fs.Bool("canonical", false, "print the canonical form instead of its digest")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: json-parser hash [-canonical] [file ...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	parseOpts := parse.options()
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = append(files, "-")
+	}
+
+	code := 0
+	for _, file := range files {
+		doc, err := parseFile(file, parseOpts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+			continue
+		}
+
+		data, err := internal.Canonicalize(doc)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			code = 1
+			continue
+		}
+
+		if *canonical {
+			fmt.Println(string(data))
+		} else {
+			fmt.Printf("%x  %s\n", sha256.Sum256(data), file)
+		}
+	}
+	return code
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+// runInfer prints a JSON Schema inferred from every document in the files.
+func runInfer(args []string) int {
+	fs := flag.NewFlagSet("infer", flag.ExitOnError)
+	parse := addParseFlags(fs)
+	ndjson := fs.Bool("ndjson", false, "read one document per line")
+	maxEnum := fs.Int("enum", 10, "most distinct strings that are still inferred as an enum, 0 disables enums")
+	output := fs.String("o", "", "write the schema to this file, - is stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: json-parser infer [-ndjson] [-enum n] [-o file] [file ...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	parseOpts := parse.options()
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = append(files, "-")
+	}
+
+	samples, err := readSamples(files, *ndjson, parseOpts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	schema := internal.InferSchema(samples, internal.InferOptions{MaxEnum: *maxEnum})
+	if err := writeOutput(*output, internal.MarshalIndent(schema, "", "  ")); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// readSamples parses every file, with ndjson every non-blank line is a
+// document of its own.
+func readSamples(files []string, ndjson bool, opts internal.ParseOptions) ([]*internal.Node, error) {
+	var samples []*internal.Node
+	for _, file := range files {
+		if !ndjson {
+			doc, err := parseFile(file, opts)
+			if err != nil {
+				return nil, err
+			}
+			samples = append(samples, doc)
+			continue
+		}
+
+		docs, err := readNDJSON(file, opts)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, docs...)
+	}
+	return samples, nil
+}
+
+// readNDJSON parses every record of the file, failing on the first one that
+// isn't valid.
+func readNDJSON(file string, opts internal.ParseOptions) ([]*internal.Node, error) {
+	f, err := openInput(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var docs []*internal.Node
+	r := internal.NewNDJSONReader(f, opts)
+	for r.Next() {
+		rec := r.Record()
+		if rec.Err != nil {
+			return nil, fmt.Errorf("%s:%d: Not valid json, details: %w", file, rec.Line, rec.Err)
+		}
+		docs = append(docs, rec.Doc)
+	}
+	return docs, r.Err()
+}
@@ -1,70 +1,154 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"runtime"
 
 	"github.com/KylerWilson01/json-parser/internal"
 )
 
+// commands holds the subcommands, the first argument picks one and falls back
+// to validating the given files when it doesn't match.
+var commands = map[string]func(args []string) int{
+	"query":       runQuery,
+	"patch":       runPatch,
+	"merge-patch": runMergePatch,
+	"diff":        runDiff,
+	"merge":       runMerge,
+	"schema":      runSchema,
+	"infer":       runInfer,
+	"gen-go":      runGenGo,
+	"fmt":         runFmt,
+	"minify":      runMinify,
+	"hash":        runHash,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := commands[os.Args[1]]; ok {
+			os.Exit(run(os.Args[2:]))
+		}
+	}
+
+	parse := addParseFlags(flag.CommandLine)
+	ndjson := flag.Bool("ndjson", false, "check every line as a document of its own and go on past bad lines")
+	seq := flag.Bool("seq", false, "check an RFC 7464 sequence of RS delimited documents")
+	concat := flag.Bool("concat", false, "check documents written one after another, like {}{}")
+	workers := flag.Int("workers", 0, "how many workers check -ndjson input, 0 uses every CPU")
+	jobs := flag.Int("j", runtime.GOMAXPROCS(0), "how many files are checked at once")
+	w := &walker{}
+	flag.BoolVar(&w.recursive, "recursive", false, "check the files in every directory given, like dir/...")
+	flag.Var(&w.include, "include", "glob of the files to check in directories, *.json when not given, can be repeated")
+	flag.Var(&w.exclude, "exclude", "glob of the files and directories to skip, can be repeated")
+	noGitignore := flag.Bool("no-gitignore", false, "don't skip the files that .gitignore files ignore")
+	format := "text"
+	flag.Func("format", "text for a line per file or json for a report of every file and its errors", func(s string) error {
+		if s != "text" && s != "json" {
+			return fmt.Errorf("must be text or json")
+		}
+		format = s
+		return nil
+	})
 	flag.Parse()
+	opts := parse.options()
 
-	fp := flag.Args()
-	if len(fp) == 0 {
-		fp = append(fp, "-")
+	mode := ""
+	switch {
+	case *ndjson:
+		mode = "ndjson"
+	case *seq:
+		mode = "seq"
+	case *concat:
+		mode = "concat"
 	}
 
-	for _, file := range fp {
-		var f *os.File
-		var err error
-
-		if file == "-" {
-			f = os.Stdin
-		} else {
-			f, err = os.Open(file)
-			if err != nil {
-				os.Exit(1)
-				return
-			}
-			defer f.Close()
-		}
+	w.gitignore = !*noGitignore
+	files, walked, err := w.expand(flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if len(files) == 0 && !walked {
+		files = append(files, "-")
+	}
 
-		data := ""
-		scanner := bufio.NewReader(f)
-		for {
-			line, err := scanner.ReadString('\n')
-			data += line
-			if err != nil {
-				if err == io.EOF {
-					break
-				}
-				fmt.Println("Something went wrong with reading the file")
-				os.Exit(0)
-			}
+	v := &validator{opts: opts, mode: mode, workers: *workers, format: format}
+	results := v.validateFiles(files, *jobs)
+	valid := 0
+	for _, res := range results {
+		if res.status == "valid" {
+			valid++
 		}
+	}
+	if format == "json" {
+		writeOutput("", internal.MarshalIndent(report(results), "", "  "))
+	} else if walked || len(files) > 1 {
+		fmt.Printf("%d files, %d valid, %d invalid\n", len(files), valid, len(files)-valid)
+	}
+	if valid < len(files) {
+		os.Exit(1)
+	}
+}
 
-		lexer := internal.NewLexer(data)
-		lexer.ValidateTokens()
+// openInput opens the file, "-" is stdin.
+func openInput(file string) (io.ReadCloser, error) {
+	if file == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(file)
+}
 
-		parser := internal.NewParser(lexer.Tokens)
-		v, err := parser.ParseTokens()
-		if err != nil {
-			fmt.Printf("Not valid json, details: %v\n", err)
-			os.Exit(1)
-			return
-		}
+// readInput reads the whole file, "-" reads from stdin.
+func readInput(file string) (string, error) {
+	if file == "-" {
+		b, err := io.ReadAll(os.Stdin)
+		return string(b), err
+	}
 
-		if v != true {
-			fmt.Println("Not valid json")
-			os.Exit(1)
-			return
-		}
+	b, err := os.ReadFile(file)
+	return string(b), err
+}
+
+// parseFile reads and parses the whole file, "-" reads from stdin. Warnings
+// are printed to stderr.
+func parseFile(file string, opts internal.ParseOptions) (*internal.Node, error) {
+	data, err := readInput(file)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := parseData(data, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: Not valid json, details: %w", file, err)
+	}
+	return doc, nil
+}
+
+// parseData parses the data, printing the warnings to stderr.
+func parseData(data string, opts internal.ParseOptions) (*internal.Node, error) {
+	lexer := internal.NewLexerWithOptions(data, opts)
+	if err := lexer.ValidateTokens(); err != nil {
+		return nil, err
+	}
+
+	parser := internal.NewParserWithOptions(lexer.Tokens, opts)
+	doc, err := parser.ParseDocument()
+	for _, w := range parser.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", w)
+	}
+	return doc, err
+}
 
-		fmt.Println("Valid json")
-		os.Exit(0)
+// writeOutput writes the data followed by a newline to the file, or to
+// stdout when the file is empty or "-".
+func writeOutput(file string, data []byte) error {
+	data = append(data, '\n')
+	if file == "" || file == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
 	}
+	return os.WriteFile(file, data, 0o644)
 }
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+// runMerge does a three-way merge and writes the result over <ours>, so it can
+// be used as a git merge driver. When there are conflicts <ours> is left as it
+// was, layout and all, and the conflicts are printed. It is also left when
+// <ours> or <theirs> has comments, Infinity or NaN that the result would lose:
+//
+//	git config merge.json.driver "json-parser merge %O %A %B"
+//	echo "*.json merge=json" >> .gitattributes
+func runMerge(args []string) int {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	parse := addParseFlags(fs)
+	asJSON := fs.Bool("json", false, "print the conflicts as a json array")
+	output := fs.String("o", "", "write the result to this file instead of <ours>, - is stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: json-parser merge [-json] [-o file] <base> <ours> <theirs>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	parseOpts := parse.options()
+
+	if fs.NArg() != 3 {
+		fs.Usage()
+		return 2
+	}
+
+	var data [3]string
+	var docs [3]*internal.Node
+	for i := range docs {
+		var err error
+		if data[i], err = readInput(fs.Arg(i)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		if docs[i], err = parseData(data[i], parseOpts); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: Not valid json, details: %v\n", fs.Arg(i), err)
+			return 2
+		}
+	}
+
+	result, conflicts := internal.Merge3(docs[0], docs[1], docs[2])
+
+	out := fs.Arg(1)
+	if *output != "" {
+		out = *output
+	}
+	if len(conflicts) == 0 && out == fs.Arg(1) {
+		for _, i := range []int{1, 2} {
+			if refuseWriteData(fs.Arg(i), data[i], parseOpts) {
+				return 2
+			}
+		}
+	}
+
+	var err error
+	switch {
+	case len(conflicts) == 0:
+		err = writeOutput(out, internal.MarshalIndent(result, "", "  "))
+	case out == "-":
+		_, err = os.Stdout.WriteString(data[1])
+	case out != fs.Arg(1):
+		err = os.WriteFile(out, []byte(data[1]), 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if *asJSON {
+		report := internal.NewArray()
+		for _, c := range conflicts {
+			report.Elements = append(report.Elements, c.Node())
+		}
+		fmt.Fprintln(os.Stderr, string(internal.MarshalIndent(report, "", "  ")))
+	} else {
+		for _, c := range conflicts {
+			fmt.Fprintln(os.Stderr, c)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+// runMergePatch applies a JSON Merge Patch (RFC 7396) to a document, or with
+// -create prints the merge patch between two documents. Like fmt, -w refuses
+// documents with comments, Infinity or NaN.
+func runMergePatch(args []string) int {
+	fs := flag.NewFlagSet("merge-patch", flag.ExitOnError)
+	parse := addParseFlags(fs)
+	create := fs.Bool("create", false, "print the merge patch that turns <original> into <modified>")
+	write := fs.Bool("w", false, "write the result back to the document instead of stdout, documents with comments, Infinity or NaN are refused")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: json-parser merge-patch [-w] <patch> [file]")
+		fmt.Fprintln(fs.Output(), "       json-parser merge-patch -create <original> <modified>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	parseOpts := parse.options()
+
+	if fs.NArg() == 0 || fs.NArg() > 2 || (*create && fs.NArg() != 2) {
+		fs.Usage()
+		return 2
+	}
+
+	file := "-"
+	if fs.NArg() == 2 {
+		file = fs.Arg(1)
+	}
+
+	first, err := parseFile(fs.Arg(0), parseOpts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	data, err := readInput(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	second, err := parseData(data, parseOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: Not valid json, details: %v\n", file, err)
+		return 1
+	}
+
+	var result *internal.Node
+	out := ""
+	if *create {
+		if result, err = internal.CreateMergePatch(first, second); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	} else {
+		if *write && file != "-" {
+			if refuseWriteData(file, data, parseOpts) {
+				return 1
+			}
+			out = file
+		}
+		result = internal.ApplyMergePatch(second, first)
+	}
+
+	if err := writeOutput(out, internal.MarshalIndent(result, "", "  ")); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+// runMinify strips the whitespace from the files and reports the bytes saved
+// on stderr. Like fmt, -w refuses files with comments, Infinity or NaN.
+func runMinify(args []string) int {
+	fs := flag.NewFlagSet("minify", flag.ExitOnError)
+	parse := addParseFlags(fs)
+	normStrings := fs.Bool("strings", false, "rewrite strings with the fewest escapes")
+	normNumbers := fs.Bool("numbers", false, "rewrite numbers in their shortest exact spelling")
+	write := fs.Bool("w", false, "write the result back to the file instead of stdout, files with comments, Infinity or NaN are refused")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: json-parser minify [-strings] [-numbers] [-w] [file ...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	parseOpts := parse.options()
+
+	opts := internal.MinifyOptions{ParseOptions: parseOpts, Strings: *normStrings, Numbers: *normNumbers}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = append(files, "-")
+	}
+
+	code := 0
+	for _, file := range files {
+		if *write && file == "-" {
+			fmt.Fprintln(os.Stderr, "can't use -w with stdin")
+			return 2
+		}
+
+		data, err := readInput(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+			continue
+		}
+
+		lexer := internal.NewLexerWithOptions(data, parseOpts)
+		err = lexer.ValidateTokens()
+		var out []byte
+		if err == nil {
+			out, err = internal.Minify(lexer.Tokens, opts)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: Not valid json, details: %v\n", file, err)
+			code = 1
+			continue
+		}
+		if *write && refuseWrite(file, lexer.Tokens) {
+			code = 1
+			continue
+		}
+
+		if *write {
+			err = os.WriteFile(file, out, 0o644)
+		} else {
+			_, err = os.Stdout.Write(append(out, '\n'))
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+			continue
+		}
+
+		saved := len(data) - len(out)
+		percent := 0.0
+		if len(data) > 0 {
+			percent = float64(saved) * 100 / float64(len(data))
+		}
+		fmt.Fprintf(os.Stderr, "%s: %d -> %d bytes, saved %d (%.1f%%)\n", file, len(data), len(out), saved, percent)
+	}
+	return code
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+// defaultMaxDepth is the nesting limit when neither -max-depth nor the preset
+// sets one, the parser recurses and a stack overflow can't be recovered.
+const defaultMaxDepth = 10000
+
+// parseFlags are the flags that pick the ParseOptions of every command that
+// reads json. The other flags override the preset no matter their order.
+type parseFlags struct {
+	fs   *flag.FlagSet
+	base internal.ParseOptions
+	set  internal.ParseOptions
+}
+
+// addParseFlags adds the parse option flags to the flag set.
+func addParseFlags(fs *flag.FlagSet) *parseFlags {
+	p := &parseFlags{fs: fs}
+	fs.Func("preset", "start from a preset: strict, rfc8259, jsonc, json5 or permissive", func(s string) error {
+		opts, err := internal.Preset(s)
+		p.base = opts
+		return err
+	})
+	fs.Func("dialect", "the dialect to read: json or json5", func(s string) error {
+		p.set.Dialect = internal.Dialect(s)
+		return p.set.Validate()
+	})
+	fs.BoolVar(&p.set.AllowComments, "comments", false, "allow // and /* */ comments")
+	fs.Func("trailing-commas", "what to do with a comma before } or ]: reject, allow or warn", func(s string) error {
+		switch s {
+		case "reject", "allow", "warn":
+		default:
+			return fmt.Errorf("must be reject, allow or warn")
+		}
+		p.set.AllowTrailingCommas = s == "allow"
+		p.set.WarnTrailingCommas = s == "warn"
+		return nil
+	})
+	fs.Func("max-depth", "most nested objects and arrays, 0 is no limit (default 10000 unless the preset sets it)", func(s string) error {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		p.set.MaxDepth = n
+		return p.set.Validate()
+	})
+	fs.Func("duplicate-keys", "what to do with a repeated member name: allow, warn or reject", func(s string) error {
+		p.set.DuplicateKeys = internal.DuplicateKeys(s)
+		return p.set.Validate()
+	})
+	fs.BoolVar(
+		&p.set.WarnUnsafeIntegers,
+		"unsafe-ints",
+		false,
+		"warn about integers bigger than 2^53-1 that JavaScript can't represent",
+	)
+	return p
+}
+
+// options returns the preset with the flags that were given applied to it.
+func (p *parseFlags) options() internal.ParseOptions {
+	opts := p.base
+	if opts.MaxDepth == 0 {
+		opts.MaxDepth = defaultMaxDepth
+	}
+	p.fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "dialect":
+			opts.Dialect = p.set.Dialect
+		case "comments":
+			opts.AllowComments = p.set.AllowComments
+		case "trailing-commas":
+			opts.AllowTrailingCommas = p.set.AllowTrailingCommas
+			opts.WarnTrailingCommas = p.set.WarnTrailingCommas
+		case "max-depth":
+			opts.MaxDepth = p.set.MaxDepth
+		case "duplicate-keys":
+			opts.DuplicateKeys = p.set.DuplicateKeys
+		case "unsafe-ints":
+			opts.WarnUnsafeIntegers = p.set.WarnUnsafeIntegers
+		}
+	})
+	return opts
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestParseFlags_MaxDepth(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected int
+	}{
+		{"Default", nil, defaultMaxDepth},
+		{"Preset limit", []string{"-preset", "strict"}, 1000},
+		{"No limit", []string{"-max-depth", "0"}, 0},
+		{"Flag", []string{"-preset", "strict", "-max-depth", "5"}, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			parse := addParseFlags(fs)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if actual := parse.options().MaxDepth; actual != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+// runPatch applies a JSON Patch (RFC 6902) to a document. Like fmt, -w
+// refuses documents with comments, Infinity or NaN.
+func runPatch(args []string) int {
+	fs := flag.NewFlagSet("patch", flag.ExitOnError)
+	parse := addParseFlags(fs)
+	write := fs.Bool("w", false, "write the result back to the document instead of stdout, documents with comments, Infinity or NaN are refused")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: json-parser patch [-w] <patch> [file]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	parseOpts := parse.options()
+
+	if fs.NArg() == 0 || fs.NArg() > 2 {
+		fs.Usage()
+		return 2
+	}
+
+	file := "-"
+	if fs.NArg() == 2 {
+		file = fs.Arg(1)
+	}
+
+	patch, err := parseFile(fs.Arg(0), parseOpts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	ops, err := internal.ParsePatch(patch)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: Not a valid patch, details: %v\n", fs.Arg(0), err)
+		return 1
+	}
+
+	data, err := readInput(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	doc, err := parseData(data, parseOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: Not valid json, details: %v\n", file, err)
+		return 1
+	}
+	if *write && file != "-" && refuseWriteData(file, data, parseOpts) {
+		return 1
+	}
+	doc, err = internal.ApplyPatch(doc, ops)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+		return 1
+	}
+
+	out := ""
+	if *write {
+		out = file
+	}
+	if err := writeOutput(out, internal.MarshalIndent(doc, "", "  ")); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
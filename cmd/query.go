@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+// runQuery prints the nodes selected by a JSONPath query, one per line.
+func runQuery(args []string) int {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	parse := addParseFlags(fs)
+	paths := fs.Bool("paths", false, "print the normalized path before every result")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: json-parser query [-paths] <query> [file ...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	parseOpts := parse.options()
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	q, err := internal.CompileQuery(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Not a valid query, details: %v\n", err)
+		return 2
+	}
+
+	files := fs.Args()[1:]
+	if len(files) == 0 {
+		files = append(files, "-")
+	}
+
+	code := 0
+	for _, file := range files {
+		doc, err := parseFile(file, parseOpts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+			continue
+		}
+
+		for _, r := range q.Select(doc) {
+			if *paths {
+				fmt.Printf("%s\t%v\n", r.Path, r.Node)
+			} else {
+				fmt.Println(r.Node)
+			}
+		}
+	}
+	return code
+}
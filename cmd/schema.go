@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+// runSchema validates every file against a JSON Schema (draft 2020-12).
+func runSchema(args []string) int {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	parse := addParseFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: json-parser schema <schema> [file ...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	parseOpts := parse.options()
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	doc, err := parseFile(fs.Arg(0), parseOpts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	schema, err := internal.CompileSchema(doc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: Not a valid schema, details: %v\n", fs.Arg(0), err)
+		return 2
+	}
+
+	files := fs.Args()[1:]
+	if len(files) == 0 {
+		files = append(files, "-")
+	}
+
+	code := 0
+	for _, file := range files {
+		inst, err := parseFile(file, parseOpts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+			continue
+		}
+
+		errs := schema.Validate(inst)
+		for _, e := range errs {
+			fmt.Printf("%s:%v\n", file, e)
+		}
+		if len(errs) > 0 {
+			code = 1
+		} else {
+			fmt.Printf("%s: Valid\n", file)
+		}
+	}
+	return code
+}
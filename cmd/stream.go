@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+// records reads a stream of documents one record at a time.
+type records interface {
+	Next() bool
+	Record() internal.Record
+	Err() error
+}
+
+// streamResult counts the records of a stream, writing a line for every
+// record that isn't valid and the warnings to res and keeping their
+// diagnostics.
+type streamResult struct {
+	file           string
+	res            *fileResult
+	total, invalid int
+}
+
+func (s *streamResult) add(rec internal.Record) error {
+	s.total++
+	for _, w := range rec.Warnings {
+		fmt.Fprintf(&s.res.warn, "%s:%d: Warning: %v\n", s.file, rec.Line, w)
+	}
+	if rec.Err != nil {
+		s.invalid++
+		fmt.Fprintf(&s.res.out, "%s:%d: Not valid json, details: %v\n", s.file, rec.Line, rec.Err)
+	}
+	s.res.diagnostics = append(s.res.diagnostics, rec.Diagnostics...)
+	return nil
+}
+
+// finish returns the error for the whole stream, err is the error that
+// stopped reading.
+func (s *streamResult) finish(err error) error {
+	if err != nil {
+		s.res.diagnostics = append(s.res.diagnostics, internal.Diagnose(err, nil))
+		return err
+	}
+	if s.invalid > 0 {
+		return fmt.Errorf("%d of %d records failed", s.invalid, s.total)
+	}
+	return nil
+}
+
+// readStream checks every record of the stream as a document of its own.
+func readStream(res *streamResult, r records) error {
+	for r.Next() {
+		res.add(r.Record())
+	}
+	return res.finish(r.Err())
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+// validator checks files, either as one document each or as a stream of
+// documents when mode is ndjson, seq or concat.
+type validator struct {
+	opts    internal.ParseOptions
+	mode    string
+	workers int
+	// format is text for a line per file or json for a report at the end
+	format string
+}
+
+// fileResult is what checking one file printed, kept until it is the file's
+// turn to be printed.
+type fileResult struct {
+	file      string
+	out, warn strings.Builder
+	// status is valid, invalid or error when the file couldn't be read or
+	// checking it panicked
+	status      string
+	diagnostics []internal.Diagnostic
+}
+
+// Node returns the result as an object with the members file, status, errors
+// and warnings.
+func (r *fileResult) Node() *internal.Node {
+	var errs, warnings []*internal.Node
+	for _, d := range r.diagnostics {
+		if d.Severity == "warning" {
+			warnings = append(warnings, d.Node())
+		} else {
+			errs = append(errs, d.Node())
+		}
+	}
+
+	n := internal.NewObject()
+	n.Set("file", internal.NewString(r.file))
+	n.Set("status", internal.NewString(r.status))
+	n.Set("errors", internal.NewArray(errs...))
+	n.Set("warnings", internal.NewArray(warnings...))
+	return n
+}
+
+// validateFiles checks the files, at most jobs at once, and prints a result
+// line for each of them in the order they were given when the format is
+// text. It returns the results in the same order.
+func (v *validator) validateFiles(files []string, jobs int) []*fileResult {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	sem := make(chan struct{}, jobs)
+	results := make([]chan *fileResult, len(files))
+	for i, file := range files {
+		results[i] = make(chan *fileResult, 1)
+		go func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] <- v.validate(file)
+		}()
+	}
+
+	done := make([]*fileResult, len(files))
+	for i, ch := range results {
+		done[i] = <-ch
+		if v.format == "text" {
+			os.Stderr.WriteString(done[i].warn.String())
+			os.Stdout.WriteString(done[i].out.String())
+		}
+	}
+	return done
+}
+
+// report returns the results as an object with the members files and
+// summary.
+func report(results []*fileResult) *internal.Node {
+	var files []*internal.Node
+	valid := 0
+	for _, res := range results {
+		files = append(files, res.Node())
+		if res.status == "valid" {
+			valid++
+		}
+	}
+
+	summary := internal.NewObject()
+	for _, m := range []struct {
+		name  string
+		count int
+	}{{"files", len(results)}, {"valid", valid}, {"invalid", len(results) - valid}} {
+		n, _ := internal.NewNumber(strconv.Itoa(m.count))
+		summary.Set(m.name, n)
+	}
+
+	n := internal.NewObject()
+	n.Set("files", internal.NewArray(files...))
+	n.Set("summary", summary)
+	return n
+}
+
+// validate checks one file, "-" is stdin.
+func (v *validator) validate(file string) *fileResult {
+	res := &fileResult{file: file}
+	err := v.safeCheck(file, res)
+	if err != nil && !hasErrors(res.diagnostics) {
+		res.diagnostics = append(res.diagnostics, internal.Diagnose(err, nil))
+	}
+	var pathErr *fs.PathError
+	var panicErr *internal.PanicError
+	if errors.As(err, &pathErr) || errors.As(err, &panicErr) {
+		res.status = "error"
+		fmt.Fprintf(&res.out, "%s: %v\n", file, err)
+		return res
+	} else if err != nil {
+		res.status = "invalid"
+		fmt.Fprintf(&res.out, "%s: Not valid json, details: %v\n", file, err)
+		return res
+	}
+	res.status = "valid"
+	fmt.Fprintf(&res.out, "%s: Valid json\n", file)
+	return res
+}
+
+// safeCheck is check with a panic turned into a PanicError, like
+// ValidateNDJSON does in its workers, so the other files are still checked.
+func (v *validator) safeCheck(file string, res *fileResult) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &internal.PanicError{Value: r}
+		}
+	}()
+	return v.check(file, res)
+}
+
+func (v *validator) check(file string, res *fileResult) error {
+	f, err := openInput(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stream := &streamResult{file: file, res: res}
+	switch v.mode {
+	case "ndjson":
+		popts := internal.ParallelOptions{ParseOptions: v.opts, Workers: v.workers}
+		return stream.finish(internal.ValidateNDJSON(context.Background(), f, popts, stream.add))
+	case "seq":
+		return readStream(stream, internal.NewSequenceReader(f, v.opts))
+	case "concat":
+		return readStream(stream, internal.NewConcatReader(f, v.opts))
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	lexer := internal.NewLexerWithOptions(string(data), v.opts)
+	if err := lexer.ValidateTokens(); err != nil {
+		res.diagnostics = append(res.diagnostics, internal.Diagnose(err, lexer.Tokens))
+		return err
+	}
+
+	parser := internal.NewParserWithOptions(lexer.Tokens, v.opts)
+	_, err = parser.ParseDocument()
+	for _, w := range parser.Warnings {
+		fmt.Fprintf(&res.warn, "%s: Warning: %v at %v\n", file, w, w.Token.Pos)
+		res.diagnostics = append(res.diagnostics, internal.DiagnoseWarning(w, lexer.Tokens))
+	}
+	if err != nil {
+		res.diagnostics = append(res.diagnostics, internal.Diagnose(err, lexer.Tokens))
+	}
+	return err
+}
+
+// hasErrors reports whether one of the diagnostics is an error.
+func hasErrors(diagnostics []internal.Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == "error" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// globList is a flag that can be given more than once.
+type globList []string
+
+func (g *globList) String() string {
+	return strings.Join(*g, ",")
+}
+
+func (g *globList) Set(s string) error {
+	if _, err := path.Match(s, ""); err != nil {
+		return err
+	}
+	*g = append(*g, s)
+	return nil
+}
+
+// walker expands directory arguments into the files inside of them.
+type walker struct {
+	recursive bool
+	// include and exclude are globs, one without a slash matches the base name
+	// and one with a slash the path relative to the walked directory
+	include, exclude globList
+	gitignore        bool
+}
+
+// expand returns the files named by the arguments, a directory ending in
+// /... or any directory when recursive is set is replaced by the files in it
+// that are included and not excluded or ignored. It reports whether a
+// directory was walked.
+func (w *walker) expand(args []string) ([]string, bool, error) {
+	var files []string
+	walked := false
+	for _, arg := range args {
+		root, dots := strings.CutSuffix(filepath.ToSlash(arg), "...")
+		if dots {
+			root = strings.TrimSuffix(root, "/")
+			if root == "" {
+				root = "."
+			}
+		} else if arg == "-" || !w.recursive {
+			files = append(files, arg)
+			continue
+		} else if info, err := os.Stat(arg); err != nil || !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+
+		found, err := w.walk(filepath.FromSlash(root))
+		if err != nil {
+			return nil, walked, err
+		}
+		files = append(files, found...)
+		walked = true
+	}
+	return files, walked, nil
+}
+
+// walk returns the files under root in lexical order.
+func (w *walker) walk(root string) ([]string, error) {
+	var files []string
+	var rules []ignoreRule
+	// repoPath turns a path relative to root into the path the rules match,
+	// relative to the repository root when root is inside of one
+	repoPath := func(rel string) string { return rel }
+	if w.gitignore {
+		var prefix string
+		var skip bool
+		if rules, prefix, skip = parentIgnoreRules(root); skip {
+			return nil, nil
+		}
+		if prefix != "" {
+			repoPath = func(rel string) string { return path.Join(prefix, rel) }
+		}
+	}
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if rel != "." {
+			if d.IsDir() && d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			skip := matchAny(w.exclude, rel) || (w.gitignore && ignored(rules, repoPath(rel), d.IsDir()))
+			if skip && d.IsDir() {
+				return filepath.SkipDir
+			} else if skip {
+				return nil
+			}
+		}
+
+		if d.IsDir() {
+			if w.gitignore {
+				data, err := os.ReadFile(filepath.Join(p, ".gitignore"))
+				if err == nil {
+					rules = append(rules, parseGitignore(repoPath(rel), string(data))...)
+				}
+			}
+			return nil
+		}
+
+		include := w.include
+		if len(include) == 0 {
+			include = globList{"*.json"}
+		}
+		if matchAny(include, rel) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// matchAny reports whether one of the globs matches the slash separated path.
+func matchAny(globs []string, rel string) bool {
+	for _, g := range globs {
+		if !strings.Contains(g, "/") {
+			if ok, _ := path.Match(g, path.Base(rel)); ok {
+				return true
+			}
+		} else if matchPath(strings.TrimPrefix(g, "/"), rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath matches a slash separated path against a glob where a ** segment
+// matches any number of segments.
+func matchPath(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// parentIgnoreRules reads the .gitignore files from the root of the git
+// repository that dir is in down to the parent of dir, their bases are
+// relative to the repository root. prefix is the path of dir relative to the
+// repository root, it is empty when dir is the root or not in a repository.
+// skip reports whether the rules ignore dir itself or one of its parents.
+func parentIgnoreRules(dir string) (rules []ignoreRule, prefix string, skip bool) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", false
+	}
+	top := abs
+	for {
+		if _, err := os.Stat(filepath.Join(top, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(top)
+		if parent == top {
+			return nil, "", false
+		}
+		top = parent
+	}
+
+	rel, err := filepath.Rel(top, abs)
+	if err != nil || rel == "." {
+		return nil, "", false
+	}
+	rel = filepath.ToSlash(rel)
+
+	base := "."
+	for _, name := range strings.Split(rel, "/") {
+		data, err := os.ReadFile(filepath.Join(top, filepath.FromSlash(base), ".gitignore"))
+		if err == nil {
+			rules = append(rules, parseGitignore(base, string(data))...)
+		}
+		base = path.Join(base, name)
+		if ignored(rules, base, true) {
+			return nil, rel, true
+		}
+	}
+	return rules, rel, false
+}
+
+// ignoreRule is one pattern of a .gitignore file.
+type ignoreRule struct {
+	// base is the directory of the .gitignore relative to the repository root,
+	// or to the walked directory outside of a repository
+	base    string
+	pattern string
+	// anchored patterns match the path relative to base, the others match
+	// the name at any depth
+	negate, dirOnly, anchored bool
+}
+
+// parseGitignore reads the patterns of the .gitignore in the directory base.
+func parseGitignore(base, data string) []ignoreRule {
+	var rules []ignoreRule
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(line, " \r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		r := ignoreRule{base: base}
+		if r.negate = strings.HasPrefix(line, "!"); r.negate {
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`)
+		if r.dirOnly = strings.HasSuffix(line, "/"); r.dirOnly {
+			line = strings.TrimSuffix(line, "/")
+		}
+		r.anchored = strings.Contains(line, "/")
+		r.pattern = strings.TrimPrefix(line, "/")
+		if r.pattern != "" {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// ignored reports whether the rules ignore the path, the last rule that
+// matches wins.
+func ignored(rules []ignoreRule, rel string, dir bool) bool {
+	ignore := false
+	for _, r := range rules {
+		if r.dirOnly && !dir {
+			continue
+		}
+		sub := rel
+		if r.base != "." {
+			var ok bool
+			if sub, ok = strings.CutPrefix(rel, r.base+"/"); !ok {
+				continue
+			}
+		}
+
+		if r.anchored && matchPath(r.pattern, sub) || !r.anchored && matchPath(r.pattern, path.Base(sub)) {
+			ignore = !r.negate
+		}
+	}
+	return ignore
+}
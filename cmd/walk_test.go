@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		expected      bool
+	}{
+		{"a/*.json", "a/b.json", true},
+		{"a/*.json", "a/b/c.json", false},
+		{"**/b.json", "b.json", true},
+		{"**/b.json", "a/c/b.json", true},
+		{"a/**", "a/b/c", true},
+		{"a/**/c", "a/c", true},
+		{"a/**/c", "a/b/d/c", true},
+		{"a/**/c", "b/c", false},
+	}
+
+	for _, tt := range tests {
+		if actual := matchPath(tt.pattern, tt.name); actual != tt.expected {
+			t.Errorf("matchPath(%q, %q): expected %v, got %v", tt.pattern, tt.name, tt.expected, actual)
+		}
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	tests := []struct {
+		name, gitignore, path string
+		dir, expected         bool
+	}{
+		{"Name at any depth", "*.log", "a/b/c.log", false, true},
+		{"Anchored", "/c.json", "c.json", false, true},
+		{"Anchored in a subdirectory", "/c.json", "a/c.json", false, false},
+		{"Slash in the middle anchors", "a/c.json", "b/a/c.json", false, false},
+		{"Negated", "*.json\n!keep.json", "keep.json", false, false},
+		{"Negation only undoes earlier rules", "!keep.json\n*.json", "keep.json", false, true},
+		{"Dir only matches a directory", "build/", "a/build", true, true},
+		{"Dir only skips a file", "build/", "a/build", false, false},
+		{"Double star", "**/gen/*.json", "a/b/gen/c.json", false, true},
+		{"Double star matches no directory", "**/gen/*.json", "gen/c.json", false, true},
+		{"Trailing double star", "out/**", "out/a/b.json", false, true},
+		{"Comments and blank lines", "# *.json\n\n", "a.json", false, false},
+		{"Escaped", `\#a.json`, "#a.json", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parseGitignore(".", tt.gitignore)
+			if actual := ignored(rules, tt.path, tt.dir); actual != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+
+	// the rules of a nested .gitignore only apply below its directory
+	rules := parseGitignore("sub", "/a.json")
+	if !ignored(rules, "sub/a.json", false) || ignored(rules, "a.json", false) {
+		t.Error("expected the rules to be relative to their directory")
+	}
+}
+
+func TestWalker_Gitignore(t *testing.T) {
+	repo := t.TempDir()
+	files := map[string]string{
+		".gitignore":         "*.tmp.json\nbuild/\n/top.json\nsub/ignored/\n",
+		"top.json":           "",
+		"sub/.gitignore":     "!keep.tmp.json\n**/gen/*.json\n",
+		"sub/top.json":       "",
+		"sub/a.json":         "",
+		"sub/a.tmp.json":     "",
+		"sub/keep.tmp.json":  "",
+		"sub/build/b.json":   "",
+		"sub/gen/c.json":     "",
+		"sub/x/gen/d.json":   "",
+		"sub/x/e.json":       "",
+		"sub/ignored/f.json": "",
+	}
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		p := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name, root string
+		expected   []string
+	}{
+		{"Repository root", ".", []string{"sub/a.json", "sub/keep.tmp.json", "sub/top.json", "sub/x/e.json"}},
+		{"Rules from the parent", "sub", []string{"sub/a.json", "sub/keep.tmp.json", "sub/top.json", "sub/x/e.json"}},
+		{"Rules from every parent", "sub/x", []string{"sub/x/e.json"}},
+		{"Ignored by a parent", "sub/ignored", nil},
+		{"Inside an ignored directory", "sub/build", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &walker{recursive: true, gitignore: true}
+			found, err := w.walk(filepath.Join(repo, filepath.FromSlash(tt.root)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var actual []string
+			for _, f := range found {
+				rel, err := filepath.Rel(repo, f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				actual = append(actual, filepath.ToSlash(rel))
+			}
+			if !slices.Equal(actual, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
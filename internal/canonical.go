@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// CanonicalError holds the error for when a document has no canonical form.
+type CanonicalError struct {
+	msg   string
+	Token Token
+}
+
+func (c *CanonicalError) Error() string {
+	return fmt.Sprintf("%s at %v, got: %q", c.msg, c.Token.Pos, c.Token.Literal)
+}
+
+// Canonicalize returns the document in the JSON Canonicalization Scheme of
+// RFC 8785: no whitespace, members sorted by the UTF-16 code units of their
+// names, numbers formatted like ECMAScript does and strings with the fewest
+// escapes. Documents with duplicate member names, numbers that overflow a
+// float64 or strings with lone surrogates have no canonical form.
+func Canonicalize(n *Node) ([]byte, error) {
+	var b strings.Builder
+	if err := canonicalize(&b, n); err != nil {
+		return nil, err
+	}
+	return []byte(b.String()), nil
+}
+
+func canonicalize(b *strings.Builder, n *Node) error {
+	switch n.Kind {
+	case ObjectKind:
+		members := slices.Clone(n.Members)
+		slices.SortStableFunc(members, func(a, b Member) int {
+			return slices.Compare(utf16.Encode([]rune(a.Name)), utf16.Encode([]rune(b.Name)))
+		})
+
+		b.WriteByte('{')
+		for i, m := range members {
+			if i > 0 {
+				if members[i-1].Name == m.Name {
+					return &CanonicalError{"Duplicate member name", m.Key}
+				}
+				b.WriteByte(',')
+			}
+			if hasLoneSurrogate(m.Key.Literal) {
+				return &CanonicalError{"Lone surrogate in member name", m.Key}
+			}
+			b.WriteString(quote(m.Name))
+			b.WriteByte(':')
+			if err := canonicalize(b, m.Value); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	case ArrayKind:
+		b.WriteByte('[')
+		for i, e := range n.Elements {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := canonicalize(b, e); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	case StringKind:
+		if hasLoneSurrogate(n.Token.Literal) {
+			return &CanonicalError{"Lone surrogate in string", n.Token}
+		}
+		b.WriteString(quote(n.text))
+	case NumberKind:
+		f, err := n.Token.Float64()
+		if err != nil {
+			return &CanonicalError{"Number doesn't fit in a float64", n.Token}
+		}
+		b.WriteString(formatES(f))
+	default:
+		b.WriteString(n.Token.Literal)
+	}
+	return nil
+}
+
+// formatES formats a finite float64 the way ECMAScript's Number.prototype.toString does.
+func formatES(f float64) string {
+	if f == 0 {
+		return "0"
+	}
+
+	sign := ""
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	// the shortest digits that round trip, as d.ddde±x
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	i := strings.IndexByte(s, 'e')
+	digits := strings.Replace(s[:i], ".", "", 1)
+	exp, _ := strconv.Atoi(s[i+1:])
+
+	// the value is 0.digits times 10^n
+	k, n := len(digits), exp+1
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits
+	}
+
+	mantissa := digits[:1]
+	if k > 1 {
+		mantissa += "." + digits[1:]
+	}
+	e := "e+"
+	if n-1 < 0 {
+		e = "e"
+	}
+	return sign + mantissa + e + strconv.Itoa(n-1)
+}
+
+// hasLoneSurrogate reports whether the string literal has a \u escape of a
+// surrogate that isn't part of a pair, unquote turns those into U+FFFD.
+func hasLoneSurrogate(lit string) bool {
+	for i := 0; i < len(lit)-1; i++ {
+		if lit[i] != '\\' {
+			continue
+		}
+		i++
+		if lit[i] != 'u' {
+			continue
+		}
+		r, ok := readHex(lit[i+1:])
+		if !ok || !utf16.IsSurrogate(r) {
+			continue
+		}
+		i += 4
+		if r >= 0xdc00 || !strings.HasPrefix(lit[i+1:], `\u`) {
+			return true
+		}
+		if r2, ok := readHex(lit[i+3:]); !ok || r2 < 0xdc00 || r2 > 0xdfff {
+			return true
+		}
+		i += 6
+	}
+	return false
+}
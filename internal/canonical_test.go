@@ -0,0 +1,79 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name, input, expected string
+	}{
+		{
+			"RFC 8785 example",
+			`{
+				"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+				"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+				"literals": [null, true, false]
+			}`,
+			`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			"Sorted by UTF-16 code units",
+			`{"\u20ac": 1, "\r": 2, "\ufb33": 3, "1": 4, "\ud83d\ude00": 5, "\u0080": 6, "\u00f6": 7}`,
+			"{\"\\r\":2,\"1\":4,\"\u0080\":6,\"ö\":7,\"€\":1,\"😀\":5,\"\ufb33\":3}",
+		},
+		{
+			"Numbers",
+			`[0, -0, 1, -1.5, 1e20, 1e21, 123456789012345678901, 0.000001, 1e-7, 9007199254740993, 5e-324, 1.7976931348623157e308]`,
+			`[0,0,1,-1.5,100000000000000000000,1e+21,123456789012345680000,0.000001,1e-7,9007199254740992,5e-324,1.7976931348623157e+308]`,
+		},
+		{"Nested", `[{"b": [], "a": {}}]`, `[{"a":{},"b":[]}]`},
+		{"Escaped backslash before u", `["\\ud800", "\ufffd"]`, `["\\ud800","` + "\ufffd" + `"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := internal.Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := internal.Canonicalize(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCanonicalize_Invalid(t *testing.T) {
+	tests := []struct {
+		name, input, expected string
+	}{
+		{"Duplicate names", `{"a": 1, "b": 2, "a": 3}`, `Duplicate member name at 1:18, got: "a"`},
+		{"Overflow", `[1e400]`, `Number doesn't fit in a float64 at 1:2, got: "1e400"`},
+		{"Lone high surrogate", `["\ud800"]`, `Lone surrogate in string at 1:2, got: "\\ud800"`},
+		{"Lone low surrogate", `["a\udc00\ud83d\ude00"]`, `Lone surrogate in string at 1:2, got: "a\\udc00\\ud83d\\ude00"`},
+		{"Reversed pair", `["\ude00\ud83d"]`, `Lone surrogate in string at 1:2, got: "\\ude00\\ud83d"`},
+		{"Lone surrogate in a name", `{"\ud83dx": 1}`, `Lone surrogate in member name at 1:2, got: "\\ud83dx"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := internal.Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = internal.Canonicalize(doc)
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("expected %s, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"slices"
+	"strconv"
+)
+
+// Node returns the operation as a patch operation object.
+func (o Operation) Node() *Node {
+	n := NewObject()
+	n.Set("op", NewString(o.Op))
+	if o.Op == "move" || o.Op == "copy" {
+		n.Set("from", NewString(o.From.String()))
+	}
+	n.Set("path", NewString(o.Path.String()))
+	if o.Value != nil {
+		n.Set("value", o.Value)
+	}
+	return n
+}
+
+// PatchNode returns the operations as a patch document.
+func PatchNode(ops []Operation) *Node {
+	n := NewArray()
+	for _, o := range ops {
+		n.Elements = append(n.Elements, o.Node())
+	}
+	return n
+}
+
+// CreatePatch returns a JSON Patch (RFC 6902) that turns a into b. Array
+// elements that only changed position are moved instead of removed and added.
+func CreatePatch(a, b *Node) []Operation {
+	return createPatch(a, b, Pointer{}, nil)
+}
+
+func createPatch(a, b *Node, path Pointer, ops []Operation) []Operation {
+	if a.Kind != b.Kind {
+		return append(ops, Operation{Op: "replace", Path: path, Value: b})
+	}
+
+	switch a.Kind {
+	case ObjectKind:
+		for _, m := range a.Members {
+			if b.Lookup(m.Name) == nil {
+				ops = append(ops, Operation{Op: "remove", Path: path.Append(m.Name)})
+			}
+		}
+		for _, m := range b.Members {
+			if b.Lookup(m.Name) != m.Value {
+				continue
+			}
+			if v := a.Lookup(m.Name); v != nil {
+				ops = createPatch(v, m.Value, path.Append(m.Name), ops)
+			} else {
+				ops = append(ops, Operation{Op: "add", Path: path.Append(m.Name), Value: m.Value})
+			}
+		}
+	case ArrayKind:
+		ops = createArrayPatch(a, b, path, ops)
+	default:
+		if !a.Equal(b) {
+			ops = append(ops, Operation{Op: "replace", Path: path, Value: b})
+		}
+	}
+	return ops
+}
+
+// createArrayPatch keeps the longest common subsequence of elements in place,
+// moves elements that are equal to one that was added, compares the remaining
+// elements between the kept ones pairwise and removes or adds the rest.
+func createArrayPatch(a, b *Node, path Pointer, ops []Operation) []Operation {
+	// source maps every element of b to the element of a it comes from, -1 is added
+	source := make([]int, len(b.Elements))
+	for j := range source {
+		source[j] = -1
+	}
+	used := make([]bool, len(a.Elements))
+	kept := commonElements(a.Elements, b.Elements)
+	for _, k := range kept {
+		source[k[1]], used[k[0]] = k[0], true
+	}
+
+	moved := make([]bool, len(b.Elements))
+	for j, e := range b.Elements {
+		if source[j] >= 0 {
+			continue
+		}
+		for i, o := range a.Elements {
+			if !used[i] && o.Equal(e) {
+				source[j], used[i], moved[j] = i, true, true
+				break
+			}
+		}
+	}
+
+	// pair the leftovers between two kept elements, they get patched in place
+	var paired [][2]int
+	i, j := 0, 0
+	for _, k := range append(kept, [2]int{len(a.Elements), len(b.Elements)}) {
+		for i < k[0] && j < k[1] {
+			switch {
+			case used[i]:
+				i++
+			case source[j] >= 0:
+				j++
+			default:
+				source[j], used[i] = i, true
+				paired = append(paired, [2]int{i, j})
+				i++
+				j++
+			}
+		}
+		i, j = k[0]+1, k[1]+1
+	}
+
+	cur := make([]int, len(a.Elements))
+	for i := range cur {
+		cur[i] = i
+	}
+	for i := len(a.Elements) - 1; i >= 0; i-- {
+		if !used[i] {
+			ops = append(ops, Operation{Op: "remove", Path: path.Append(strconv.Itoa(i))})
+			cur = slices.Delete(cur, i, i+1)
+		}
+	}
+
+	// final is the position in b of every element of a that is still in cur
+	final := make([]int, len(a.Elements))
+	placed := make([]bool, len(a.Elements))
+	for j, i := range source {
+		if i >= 0 {
+			final[i] = j
+			placed[i] = !moved[j]
+		}
+	}
+
+	for j, i := range source {
+		if !moved[j] {
+			continue
+		}
+
+		from := slices.Index(cur, i)
+		cur = slices.Delete(cur, from, from+1)
+		// insert right after the closest element that is already in place and comes before it in b
+		to := 0
+		for p, c := range cur {
+			if placed[c] && final[c] < j {
+				to = p + 1
+			}
+		}
+		cur = slices.Insert(cur, to, i)
+		placed[i] = true
+
+		if from != to {
+			ops = append(ops, Operation{
+				Op:   "move",
+				From: path.Append(strconv.Itoa(from)),
+				Path: path.Append(strconv.Itoa(to)),
+			})
+		}
+	}
+
+	for j, i := range source {
+		if i < 0 {
+			ops = append(ops, Operation{Op: "add", Path: path.Append(strconv.Itoa(j)), Value: b.Elements[j]})
+		}
+	}
+
+	for _, p := range paired {
+		ops = createPatch(a.Elements[p[0]], b.Elements[p[1]], path.Append(strconv.Itoa(p[1])), ops)
+	}
+	return ops
+}
@@ -0,0 +1,88 @@
+package internal_test
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func TestCreatePatch(t *testing.T) {
+	tests := []struct {
+		name, a, b, expected string
+	}{
+		{"Equal", `{"a": [1, 2]}`, `{"a": [1, 2]}`, `[]`},
+		{"Member changes", `{"a": 1, "b": 2}`, `{"b": 3, "c": 4}`,
+			`[{"op":"remove","path":"/a"},{"op":"replace","path":"/b","value":3},{"op":"add","path":"/c","value":4}]`},
+		{"Kind change", `{"a": {"b": 1}}`, `{"a": [1]}`, `[{"op":"replace","path":"/a","value":[1]}]`},
+		{"Array insert", `[1, 2, 3]`, `[1, 4, 2, 3]`, `[{"op":"add","path":"/1","value":4}]`},
+		{"Array remove", `[1, 2, 3]`, `[1, 3]`, `[{"op":"remove","path":"/1"}]`},
+		{"Move to end", `[{"id": 1}, 2, 3, 4]`, `[2, 3, 4, {"id": 1}]`,
+			`[{"op":"move","from":"/0","path":"/3"}]`},
+		{"Move to front", `[1, 2, 3, {"id": 4}]`, `[{"id": 4}, 1, 2, 3]`,
+			`[{"op":"move","from":"/3","path":"/0"}]`},
+		{"Element patched in place", `[1, {"a": 1}, 3]`, `[1, {"a": 2}, 3]`,
+			`[{"op":"replace","path":"/1/a","value":2}]`},
+		{"Escaped names", `{"a/b": 1}`, `{"a/b": 1, "~": 2}`, `[{"op":"add","path":"/~0","value":2}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := internal.Parse(tt.a)
+			b, _ := internal.Parse(tt.b)
+
+			ops := internal.CreatePatch(a, b)
+			if actual := internal.PatchNode(ops).String(); actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+
+			result, err := internal.ApplyPatch(a, ops)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !result.Equal(b) {
+				t.Errorf("applying the patch gave %v, expected %v", result, b)
+			}
+		})
+	}
+}
+
+func TestCreatePatch_RoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	randomArray := func() string {
+		var s []string
+		for range r.Intn(8) {
+			if r.Intn(4) == 0 {
+				s = append(s, fmt.Sprintf(`{"k": %d}`, r.Intn(3)))
+			} else {
+				s = append(s, fmt.Sprint(r.Intn(6)))
+			}
+		}
+		return "[" + strings.Join(s, ",") + "]"
+	}
+
+	for i := range 2000 {
+		a, _ := internal.Parse(randomArray())
+		b, _ := internal.Parse(randomArray())
+
+		ops := internal.CreatePatch(a, b)
+		result, err := internal.ApplyPatch(a, ops)
+		if err != nil {
+			t.Fatalf("%d: %v -> %v: %v", i, a, b, err)
+		}
+		if !result.Equal(b) {
+			t.Fatalf("%d: %v -> %v gave %v with %v", i, a, b, result, internal.PatchNode(ops))
+		}
+
+		// round trip the patch through its json text
+		patch, err := internal.Parse(internal.PatchNode(ops).String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := internal.ParsePatch(patch); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package internal
+
+import (
+	"strings"
+)
+
+// CSTToken is a token together with the text in front of it.
+type CSTToken struct {
+	Token
+	// Leading is the whitespace and comments between the previous token and
+	// this one
+	Leading string
+}
+
+// Raw returns the token as it was written in the input.
+func (t CSTToken) Raw() string {
+	return rawToken(t.Token)
+}
+
+// CSTNode is a value in the CST, it is made of the tokens from Start up to
+// but not including End.
+type CSTNode struct {
+	Kind       NodeKind
+	Start, End int
+	Members    []CSTMember
+	Elements   []*CSTNode
+}
+
+// CSTMember is a member of an object in the CST, Key is the index of the
+// token of its name.
+type CSTMember struct {
+	Key   int
+	Name  string
+	Value *CSTNode
+}
+
+// CST is a concrete syntax tree that keeps the text between the tokens, so
+// String reproduces the input byte for byte. The edit methods only rewrite
+// the text of the value or member they touch and keep the rest as it was.
+type CST struct {
+	Tokens []CSTToken
+	// Trailing is the text after the last token
+	Trailing string
+	Root     *CSTNode
+	opts     ParseOptions
+}
+
+// ParseCST parses the input into a CST.
+func ParseCST(input string) (*CST, error) {
+	return ParseCSTWithOptions(input, ParseOptions{})
+}
+
+// ParseCSTWithOptions parses the input into a CST using the options, edits
+// are checked with the same options.
+func ParseCSTWithOptions(input string, opts ParseOptions) (*CST, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	l := NewLexerWithOptions(input, opts)
+	if err := l.ValidateTokens(); err != nil {
+		return nil, err
+	}
+	if _, err := NewParserWithOptions(l.Tokens, opts).ParseDocument(); err != nil {
+		return nil, err
+	}
+
+	c := &CST{opts: opts}
+	end := 0
+	for _, t := range l.Tokens {
+		// comments are kept as part of the text in front of the next token
+		if t.Type == Comment {
+			continue
+		}
+		c.Tokens = append(c.Tokens, CSTToken{Token: t, Leading: input[end:t.Pos.Offset]})
+		end = t.Pos.Offset + len(rawToken(t))
+	}
+	c.Trailing = input[end:]
+
+	pos := 0
+	c.Root = c.node(&pos)
+	return c, nil
+}
+
+// String returns the text of the tree.
+func (c *CST) String() string {
+	var b strings.Builder
+	for _, t := range c.Tokens {
+		b.WriteString(t.Leading)
+		b.WriteString(t.Raw())
+	}
+	b.WriteString(c.Trailing)
+	return b.String()
+}
+
+// Text returns the text of the node without the whitespace around it.
+func (c *CST) Text(n *CSTNode) string {
+	return c.String()[c.start(n.Start):c.end(n.End)]
+}
+
+// Lookup returns the node the pointer refers to.
+func (c *CST) Lookup(path Pointer) (*CSTNode, error) {
+	n := c.Root
+	for i, seg := range path {
+		switch n.Kind {
+		case ObjectKind:
+			m := n.member(seg)
+			if m < 0 {
+				return nil, &PointerError{CodePointerNotFound, "Could not resolve segment", path.String(), seg}
+			}
+			n = n.Members[m].Value
+		case ArrayKind:
+			idx, err := path.index(i, len(n.Elements))
+			if err != nil {
+				return nil, err
+			}
+			if idx >= len(n.Elements) {
+				return nil, &PointerError{CodePointerNotFound, "Could not resolve segment", path.String(), seg}
+			}
+			n = n.Elements[idx]
+		default:
+			return nil, &PointerError{CodePointerMismatch, "Can't index into a " + string(n.Kind) + " with segment", path.String(), seg}
+		}
+	}
+	return n, nil
+}
+
+// Set replaces the value at the path, a missing object member is added after
+// the last member and "-" adds an element to the end of an array.
+func (c *CST) Set(path Pointer, v *Node) error {
+	if len(path) == 0 {
+		return c.splice(c.start(c.Root.Start), c.end(c.Root.End), string(Marshal(v)))
+	}
+
+	parent, err := c.Lookup(path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	last := path[len(path)-1]
+
+	switch parent.Kind {
+	case ObjectKind:
+		if m := parent.member(last); m >= 0 {
+			n := parent.Members[m].Value
+			return c.splice(c.start(n.Start), c.end(n.End), string(Marshal(v)))
+		}
+		return c.insert(parent, last, v)
+	case ArrayKind:
+		idx, err := path.index(len(path)-1, len(parent.Elements))
+		if err != nil {
+			return err
+		}
+		if idx == len(parent.Elements) {
+			return c.insert(parent, "", v)
+		}
+		if idx > len(parent.Elements) {
+			return &PointerError{CodeIndexOutOfRange, "Array index out of range", path.String(), last}
+		}
+		n := parent.Elements[idx]
+		return c.splice(c.start(n.Start), c.end(n.End), string(Marshal(v)))
+	}
+	return &PointerError{CodePointerMismatch, "Can't index into a " + string(parent.Kind) + " with segment", path.String(), last}
+}
+
+// InsertMember adds a member after the last member of the object at the path.
+func (c *CST) InsertMember(path Pointer, name string, v *Node) error {
+	n, err := c.Lookup(path)
+	if err != nil {
+		return err
+	}
+	if n.Kind != ObjectKind {
+		return &PointerError{CodePointerMismatch, "Can't insert a member into the " + string(n.Kind) + " at", path.String(), ""}
+	}
+	if n.member(name) >= 0 {
+		return &PointerError{CodeMemberExists, "Member already exists", path.Append(name).String(), name}
+	}
+	return c.insert(n, name, v)
+}
+
+// Delete removes the member or element at the path together with its comma.
+func (c *CST) Delete(path Pointer) error {
+	if len(path) == 0 {
+		return &PointerError{CodeRootNotAllowed, "Can't delete the root", "", ""}
+	}
+	parent, err := c.Lookup(path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	if _, err := c.Lookup(path); err != nil {
+		return err
+	}
+
+	// the token spans of the members or elements
+	var items [][2]int
+	idx := 0
+	switch parent.Kind {
+	case ObjectKind:
+		idx = parent.member(path[len(path)-1])
+		for _, m := range parent.Members {
+			items = append(items, [2]int{m.Key, m.Value.End})
+		}
+	case ArrayKind:
+		idx, _ = path.index(len(path)-1, len(parent.Elements))
+		for _, e := range parent.Elements {
+			items = append(items, [2]int{e.Start, e.End})
+		}
+	}
+
+	switch {
+	case len(items) == 1:
+		return c.splice(c.end(parent.Start+1), c.start(parent.End-1), "")
+	case idx > 0:
+		return c.splice(c.end(items[idx-1][1]), c.end(items[idx][1]), "")
+	default:
+		return c.splice(c.start(items[0][0]), c.start(items[1][0]), "")
+	}
+}
+
+// Rename changes the name of the member at the path.
+func (c *CST) Rename(path Pointer, name string) error {
+	if len(path) == 0 {
+		return &PointerError{CodeRootNotAllowed, "Can't rename the root", "", ""}
+	}
+	parent, err := c.Lookup(path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	if parent.Kind != ObjectKind {
+		return &PointerError{CodePointerMismatch, "Can't rename a member of the " + string(parent.Kind) + " with segment", path.String(), path[len(path)-1]}
+	}
+
+	m := parent.member(path[len(path)-1])
+	if m < 0 {
+		return &PointerError{CodePointerNotFound, "Could not resolve segment", path.String(), path[len(path)-1]}
+	}
+	if other := parent.member(name); other >= 0 && other != m {
+		return &PointerError{CodeMemberExists, "Member already exists", path[:len(path)-1].Append(name).String(), name}
+	}
+
+	key := parent.Members[m].Key
+	return c.splice(c.start(key), c.end(key+1), quote(name))
+}
+
+// member returns the index of the last member with the name, or -1.
+func (n *CSTNode) member(name string) int {
+	for i := len(n.Members) - 1; i >= 0; i-- {
+		if n.Members[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// insert adds a member or element after the last one of the container,
+// copying the indentation of the last one. The name is ignored for arrays.
+func (c *CST) insert(n *CSTNode, name string, v *Node) error {
+	text := string(Marshal(v))
+	if n.Kind == ObjectKind {
+		sep := ": "
+		if len(n.Members) > 0 {
+			value := c.Tokens[n.Members[len(n.Members)-1].Value.Start].Leading
+			if strings.Trim(value, " \t") == "" {
+				sep = ":" + value
+			}
+		}
+		text = quote(name) + sep + text
+	}
+
+	var first, last int
+	switch {
+	case n.Kind == ObjectKind && len(n.Members) > 0:
+		first, last = n.Members[len(n.Members)-1].Key, n.End-1
+	case n.Kind == ArrayKind && len(n.Elements) > 0:
+		first, last = n.Elements[len(n.Elements)-1].Start, n.End-1
+	default:
+		at := c.end(n.Start + 1)
+		return c.splice(at, at, text)
+	}
+
+	// skip a trailing comma
+	for last > first && c.Tokens[last-1].Type == Comma {
+		last--
+	}
+	at := c.end(last)
+
+	indent := " "
+	leading := c.Tokens[first].Leading
+	if strings.Trim(leading, " \t") == "" {
+		indent = leading
+	} else if i := strings.LastIndexByte(leading, '\n'); i >= 0 {
+		indent = "\n" + leading[i+1:]
+		if i > 0 && leading[i-1] == '\r' {
+			indent = "\r" + indent
+		}
+	}
+	return c.splice(at, at, ","+indent+text)
+}
+
+// start returns the offset of the i-th token.
+func (c *CST) start(i int) int {
+	if i >= len(c.Tokens) {
+		return c.end(len(c.Tokens))
+	}
+	return c.Tokens[i].Pos.Offset
+}
+
+// end returns the offset right after the token before the i-th one.
+func (c *CST) end(i int) int {
+	if i == 0 {
+		return 0
+	}
+	t := c.Tokens[i-1]
+	return t.Pos.Offset + len(t.Raw())
+}
+
+// splice replaces the text between the offsets and parses the tree again,
+// the tree is left as it was when the result isn't valid.
+func (c *CST) splice(start, end int, text string) error {
+	src := c.String()
+	next, err := ParseCSTWithOptions(src[:start]+text+src[end:], c.opts)
+	if err != nil {
+		return err
+	}
+	*c = *next
+	return nil
+}
+
+// node builds the node starting at the token at pos, the tokens are known to
+// be a valid document.
+func (c *CST) node(pos *int) *CSTNode {
+	n := &CSTNode{Start: *pos}
+	t := c.Tokens[*pos]
+	*pos++
+
+	switch t.Type {
+	case OpeningCurly:
+		n.Kind = ObjectKind
+		for c.Tokens[*pos].Type != ClosingCurly {
+			key := *pos
+			name, _ := unquote(c.Tokens[key].Literal)
+			*pos += 2
+			n.Members = append(n.Members, CSTMember{Key: key, Name: name, Value: c.node(pos)})
+			if c.Tokens[*pos].Type == Comma {
+				*pos++
+			}
+		}
+		*pos++
+	case OpeningBracket:
+		n.Kind = ArrayKind
+		for c.Tokens[*pos].Type != ClosingBracket {
+			n.Elements = append(n.Elements, c.node(pos))
+			if c.Tokens[*pos].Type == Comma {
+				*pos++
+			}
+		}
+		*pos++
+	case ValueString, NameString:
+		n.Kind = StringKind
+	case Number:
+		n.Kind = NumberKind
+	case True, False:
+		n.Kind = BoolKind
+	default:
+		n.Kind = NullKind
+	}
+
+	n.End = *pos
+	return n
+}
+
+// rawToken returns the token as it was written in the input.
+func rawToken(t Token) string {
+	if t.Raw != "" {
+		return t.Raw
+	}
+	if t.Type == ValueString || t.Type == NameString {
+		return `"` + t.Literal + `"`
+	}
+	return t.Literal
+}
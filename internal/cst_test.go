@@ -0,0 +1,204 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+const cstConfig = "{\n" +
+	"    \"name\" :\t\"app\",\n" +
+	"    \"ports\": [ 80,443 ],\n" +
+	"\n" +
+	"    \"db\": {\"host\": \"a\", \"port\": 5432}\n" +
+	"}\n"
+
+func TestParseCST_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name, input string
+	}{
+		{"Config", cstConfig},
+		{"Scalar", "  1.50e+1 \n"},
+		{"Escapes", `{"a\/b" : "é\n"}`},
+		{"CRLF", "[\r\n\t1,\r\n\t{ }\r\n]\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := internal.ParseCST(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := c.String(); actual != tt.input {
+				t.Errorf("expected %q, got %q", tt.input, actual)
+			}
+		})
+	}
+}
+
+func TestCST_Lookup(t *testing.T) {
+	c, err := internal.ParseCST(cstConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := c.Lookup(internal.Pointer{"ports"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := c.Text(n); actual != "[ 80,443 ]" {
+		t.Errorf("expected %q, got %q", "[ 80,443 ]", actual)
+	}
+	if n.Kind != internal.ArrayKind || len(n.Elements) != 2 {
+		t.Errorf("expected an array with 2 elements, got %v", n)
+	}
+
+	if _, err := c.Lookup(internal.Pointer{"db", "user"}); err == nil {
+		t.Error("expected an error for a missing member")
+	}
+}
+
+func TestCST_Edit(t *testing.T) {
+	tests := []struct {
+		name, input string
+		edit        func(c *internal.CST) error
+		expected    string
+	}{
+		{
+			"Set value", cstConfig,
+			func(c *internal.CST) error {
+				return c.Set(internal.Pointer{"db", "port"}, mustParse(t, `{"a": [1]}`))
+			},
+			"{\n    \"name\" :\t\"app\",\n    \"ports\": [ 80,443 ],\n\n    \"db\": {\"host\": \"a\", \"port\": {\"a\":[1]}}\n}\n",
+		},
+		{
+			"Set new member", cstConfig,
+			func(c *internal.CST) error { return c.Set(internal.Pointer{"debug"}, internal.NewBool(true)) },
+			"{\n    \"name\" :\t\"app\",\n    \"ports\": [ 80,443 ],\n\n    \"db\": {\"host\": \"a\", \"port\": 5432},\n    \"debug\": true\n}\n",
+		},
+		{
+			"Append element", cstConfig,
+			func(c *internal.CST) error { return c.Set(internal.Pointer{"ports", "-"}, mustParse(t, `8080`)) },
+			"{\n    \"name\" :\t\"app\",\n    \"ports\": [ 80,443,8080 ],\n\n    \"db\": {\"host\": \"a\", \"port\": 5432}\n}\n",
+		},
+		{
+			"Set root", " [1] \n",
+			func(c *internal.CST) error { return c.Set(internal.Pointer{}, internal.NewNull()) },
+			" null \n",
+		},
+		{
+			"Insert member", cstConfig,
+			func(c *internal.CST) error {
+				return c.InsertMember(internal.Pointer{"db"}, "user", internal.NewString("root"))
+			},
+			"{\n    \"name\" :\t\"app\",\n    \"ports\": [ 80,443 ],\n\n    \"db\": {\"host\": \"a\", \"port\": 5432, \"user\": \"root\"}\n}\n",
+		},
+		{
+			"Insert into empty object", "{ \"a\": {} }",
+			func(c *internal.CST) error { return c.InsertMember(internal.Pointer{"a"}, "b", internal.NewArray()) },
+			"{ \"a\": {\"b\": []} }",
+		},
+		{
+			"Delete first member", cstConfig,
+			func(c *internal.CST) error { return c.Delete(internal.Pointer{"name"}) },
+			"{\n    \"ports\": [ 80,443 ],\n\n    \"db\": {\"host\": \"a\", \"port\": 5432}\n}\n",
+		},
+		{
+			"Delete last member", cstConfig,
+			func(c *internal.CST) error { return c.Delete(internal.Pointer{"db"}) },
+			"{\n    \"name\" :\t\"app\",\n    \"ports\": [ 80,443 ]\n}\n",
+		},
+		{
+			"Delete element", cstConfig,
+			func(c *internal.CST) error { return c.Delete(internal.Pointer{"ports", "1"}) },
+			"{\n    \"name\" :\t\"app\",\n    \"ports\": [ 80 ],\n\n    \"db\": {\"host\": \"a\", \"port\": 5432}\n}\n",
+		},
+		{
+			"Delete only element", "[ [ 1 ] ]",
+			func(c *internal.CST) error { return c.Delete(internal.Pointer{"0", "0"}) },
+			"[ [] ]",
+		},
+		{
+			"Rename key", cstConfig,
+			func(c *internal.CST) error { return c.Rename(internal.Pointer{"db", "host"}, "hostname") },
+			"{\n    \"name\" :\t\"app\",\n    \"ports\": [ 80,443 ],\n\n    \"db\": {\"hostname\": \"a\", \"port\": 5432}\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := internal.ParseCST(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.edit(c); err != nil {
+				t.Fatal(err)
+			}
+			if actual := c.String(); actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCST_EditErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(c *internal.CST) error
+	}{
+		{"Set missing parent", func(c *internal.CST) error { return c.Set(internal.Pointer{"x", "y"}, internal.NewNull()) }},
+		{"Set past the end", func(c *internal.CST) error { return c.Set(internal.Pointer{"ports", "5"}, internal.NewNull()) }},
+		{"Insert existing", func(c *internal.CST) error { return c.InsertMember(internal.Pointer{}, "db", internal.NewNull()) }},
+		{"Insert into array", func(c *internal.CST) error { return c.InsertMember(internal.Pointer{"ports"}, "a", internal.NewNull()) }},
+		{"Delete missing", func(c *internal.CST) error { return c.Delete(internal.Pointer{"missing"}) }},
+		{"Delete root", func(c *internal.CST) error { return c.Delete(internal.Pointer{}) }},
+		{"Rename to existing", func(c *internal.CST) error { return c.Rename(internal.Pointer{"name"}, "db") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := internal.ParseCST(cstConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.edit(c); err == nil {
+				t.Error("expected an error")
+			}
+			if c.String() != cstConfig {
+				t.Errorf("expected the tree to be unchanged, got %q", c.String())
+			}
+		})
+	}
+}
+
+func mustParse(t *testing.T, input string) *internal.Node {
+	t.Helper()
+	n, err := internal.Parse(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestParseCSTWithOptions_Comments(t *testing.T) {
+	input := "{\n  // the port\n  \"port\": 80, /* http */\n  \"tls\": false,\n}\n// end\n"
+	c, err := internal.ParseCSTWithOptions(input, internal.JSONC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := c.String(); actual != input {
+		t.Errorf("expected %q, got %q", input, actual)
+	}
+
+	if err := c.Set(internal.Pointer{"port"}, mustParse(t, `443`)); err != nil {
+		t.Fatal(err)
+	}
+	expected := "{\n  // the port\n  \"port\": 443, /* http */\n  \"tls\": false,\n}\n// end\n"
+	if actual := c.String(); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	if _, err := internal.ParseCST(input); err == nil {
+		t.Error("expected comments to be rejected without options")
+	}
+}
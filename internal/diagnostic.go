@@ -0,0 +1,193 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Diagnostic describes an error or warning for a report.
+type Diagnostic struct {
+	// Severity is "error" or "warning"
+	Severity string
+	// Code names the kind of problem, like CodeExpectedValue, it is CodeError
+	// for errors that don't come from this package
+	Code    string
+	Message string
+	// Pos is where the problem is, it is zero when that isn't known
+	Pos Position
+	// Path is the normalized path of the value the problem is in, like
+	// $['a'][0], it is empty when that isn't known
+	Path string
+}
+
+// Codes of errors and warnings, they name the kind of problem for programs
+// and stay the same when a message is reworded.
+const (
+	// CodeError is the code of errors that don't come from this package
+	CodeError                  = "error"
+	CodeIllegalToken           = "illegal-token"
+	CodeUnbalancedBrackets     = "unbalanced-brackets"
+	CodeUnclosedBrackets       = "unclosed-brackets"
+	CodeUnterminatedString     = "unterminated-string"
+	CodeUnterminatedComment    = "unterminated-comment"
+	CodeInvalidString          = "invalid-string"
+	CodeInvalidEscape          = "invalid-escape"
+	CodeInvalidNumber          = "invalid-number"
+	CodeTooDeep                = "too-deep"
+	CodeDuplicateKey           = "duplicate-key"
+	CodeExpectedColon          = "expected-colon"
+	CodeExpectedComma          = "expected-comma"
+	CodeExpectedMemberName     = "expected-member-name"
+	CodeExpectedValue          = "expected-value"
+	CodeTrailingData           = "trailing-data"
+	CodeUnexpectedEnd          = "unexpected-end"
+	CodeMissingRecordSeparator = "missing-record-separator"
+	CodeTruncatedValue         = "truncated-value"
+	CodeUnsafeInteger          = "unsafe-integer"
+	CodeTrailingComma          = "trailing-comma"
+	CodeInvalidPointer         = "invalid-pointer"
+	CodePointerNotFound        = "pointer-not-found"
+	CodePointerMismatch        = "pointer-mismatch"
+	CodeInvalidIndex           = "invalid-index"
+	CodeIndexOutOfRange        = "index-out-of-range"
+	CodeMemberExists           = "member-exists"
+	CodeRootNotAllowed         = "root-not-allowed"
+)
+
+// errorCode returns the code of an error from this package, CodeError for
+// any other error.
+func errorCode(err error) string {
+	var tokenErr *TokenError
+	var syntaxErr *SyntaxError
+	var pointerErr *PointerError
+	var streamErr *StreamError
+
+	switch {
+	case errors.As(err, &tokenErr):
+		return tokenErr.Code
+	case errors.As(err, &syntaxErr):
+		return syntaxErr.Code
+	case errors.As(err, &pointerErr):
+		return pointerErr.Code
+	case errors.As(err, &streamErr):
+		return streamErr.Code
+	}
+	return CodeError
+}
+
+// Diagnose describes the error, tokens are the tokens read before it was
+// found and give its path.
+func Diagnose(err error, tokens []Token) Diagnostic {
+	var tokenErr *TokenError
+	var syntaxErr *SyntaxError
+	var streamErr *StreamError
+
+	d := Diagnostic{Severity: "error", Code: errorCode(err), Message: err.Error()}
+	switch {
+	case errors.As(err, &tokenErr):
+		d.Message = strings.TrimSpace(tokenErr.msg + " " + tokenErr.arg)
+		d.Pos = tokenErr.Pos
+	case errors.As(err, &syntaxErr):
+		d.Message = fmt.Sprintf("%s, got: %q", syntaxErr.msg, syntaxErr.Token.Literal)
+		d.Pos = syntaxErr.Token.Pos
+	case errors.As(err, &streamErr):
+		d.Message = streamErr.msg
+		d.Pos = streamErr.Pos
+	default:
+		return d
+	}
+
+	d.Path = tokenPath(tokens, d.Pos)
+	return d
+}
+
+// DiagnoseWarning describes the warning, tokens are the tokens of the
+// document and give its path.
+func DiagnoseWarning(w Warning, tokens []Token) Diagnostic {
+	return Diagnostic{
+		Severity: "warning",
+		Code:     w.Code,
+		Message:  w.String(),
+		Pos:      w.Token.Pos,
+		Path:     tokenPath(tokens, w.Token.Pos),
+	}
+}
+
+// tokenPath returns the normalized path of the value at the position, going
+// by the tokens in front of it. A member name at the position is part of
+// the path. Without a position it is the path after the last token.
+func tokenPath(tokens []Token, pos Position) string {
+	type frame struct {
+		object bool
+		name   string
+		named  bool
+		index  int
+	}
+	var stack []frame
+
+	for _, t := range tokens {
+		if pos.Line > 0 && (t.Pos.Offset > pos.Offset || t.Pos.Offset == pos.Offset && t.Type != NameString) {
+			break
+		}
+
+		switch t.Type {
+		case OpeningCurly, OpeningBracket:
+			stack = append(stack, frame{object: t.Type == OpeningCurly})
+		case ClosingCurly, ClosingBracket:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case Comma:
+			if len(stack) > 0 {
+				top := &stack[len(stack)-1]
+				top.index++
+				top.named = false
+			}
+		case NameString:
+			if len(stack) > 0 {
+				top := &stack[len(stack)-1]
+				top.name, _ = unquote(t.Literal)
+				top.named = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("$")
+	for _, f := range stack {
+		switch {
+		case f.object && f.named:
+			b.WriteString(normalizedName(f.name))
+		case !f.object:
+			b.WriteString("[" + strconv.Itoa(f.index) + "]")
+		}
+	}
+	return b.String()
+}
+
+// Node returns the diagnostic as an object with the members severity, code,
+// message, line, column, offset and path, the position and path are null
+// when they aren't known.
+func (d Diagnostic) Node() *Node {
+	n := NewObject()
+	n.Set("severity", NewString(d.Severity))
+	n.Set("code", NewString(d.Code))
+	n.Set("message", NewString(d.Message))
+	if d.Pos.Line > 0 {
+		n.Set("line", newInt(d.Pos.Line))
+		n.Set("column", newInt(d.Pos.Column))
+		n.Set("offset", newInt(d.Pos.Offset))
+	} else {
+		n.Set("line", NewNull())
+		n.Set("column", NewNull())
+		n.Set("offset", NewNull())
+	}
+	if d.Path != "" {
+		n.Set("path", NewString(d.Path))
+	} else {
+		n.Set("path", NewNull())
+	}
+	return n
+}
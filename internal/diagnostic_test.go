@@ -0,0 +1,188 @@
+package internal_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func TestDiagnose(t *testing.T) {
+	tests := []struct {
+		name, input string
+		opts        internal.ParseOptions
+		expected    internal.Diagnostic
+	}{
+		{
+			"Illegal token", `{"a": [1, @]}`, internal.ParseOptions{},
+			internal.Diagnostic{
+				Severity: "error", Code: "illegal-token", Message: "Not a legal token @",
+				Pos: internal.Position{Offset: 10, Line: 1, Column: 11}, Path: "$['a'][1]",
+			},
+		},
+		{
+			"Missing value", "{\"a\": {\"b\": [true,\n  ]}}", internal.ParseOptions{},
+			internal.Diagnostic{
+				Severity: "error", Code: "expected-value", Message: `Expected a value, got: "]"`,
+				Pos: internal.Position{Offset: 21, Line: 2, Column: 3}, Path: "$['a']['b'][1]",
+			},
+		},
+		{
+			"Duplicate key", `[{"a": 1}, {"a": 1, "a": 2}]`, internal.Strict,
+			internal.Diagnostic{
+				Severity: "error", Code: "duplicate-key", Message: `Duplicate member name, got: "a"`,
+				Pos: internal.Position{Offset: 20, Line: 1, Column: 21}, Path: "$[1]['a']",
+			},
+		},
+		{
+			"Unclosed object", `{"a": `, internal.ParseOptions{},
+			internal.Diagnostic{
+				Severity: "error", Code: "unclosed-brackets", Message: "Length of the state should be 0. Instead got 1",
+				Pos: internal.Position{Offset: 6, Line: 1, Column: 7}, Path: "$['a']",
+			},
+		},
+		{
+			"Bad number", `{"it's": -01}`, internal.ParseOptions{},
+			internal.Diagnostic{
+				Severity: "error", Code: "invalid-number", Message: `Not a valid number, got: "-01"`,
+				Pos: internal.Position{Offset: 9, Line: 1, Column: 10}, Path: `$['it\'s']`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := internal.NewLexerWithOptions(tt.input, tt.opts)
+			err := l.ValidateTokens()
+			if err == nil {
+				_, err = internal.NewParserWithOptions(l.Tokens, tt.opts).ParseDocument()
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			if actual := internal.Diagnose(err, l.Tokens); actual != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, actual)
+			}
+		})
+	}
+
+	other := internal.Diagnose(errors.New("disk on fire"), nil)
+	if other.Code != "error" || other.Message != "disk on fire" || other.Path != "" {
+		t.Errorf("expected a plain error, got %+v", other)
+	}
+}
+
+func TestDiagnoseWarning(t *testing.T) {
+	input := `{"ids": [1, 9007199254740993]}`
+	l := internal.NewLexer(input)
+	if err := l.ValidateTokens(); err != nil {
+		t.Fatal(err)
+	}
+	p := internal.NewParserWithOptions(l.Tokens, internal.ParseOptions{WarnUnsafeIntegers: true})
+	if _, err := p.ParseDocument(); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %v", p.Warnings)
+	}
+
+	expected := internal.Diagnostic{
+		Severity: "warning", Code: "unsafe-integer", Message: "Integer is bigger than 2^53-1 9007199254740993",
+		Pos: internal.Position{Offset: 12, Line: 1, Column: 13}, Path: "$['ids'][1]",
+	}
+	if actual := internal.DiagnoseWarning(p.Warnings[0], l.Tokens); actual != expected {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+}
+
+func TestDiagnostic_Node(t *testing.T) {
+	d := internal.Diagnostic{
+		Severity: "error", Code: "expected-value", Message: "Expected a value",
+		Pos: internal.Position{Offset: 6, Line: 1, Column: 7}, Path: "$['a']",
+	}
+	expected := `{"severity":"error","code":"expected-value","message":"Expected a value",` +
+		`"line":1,"column":7,"offset":6,"path":"$['a']"}`
+	if actual := d.Node().String(); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+
+	d = internal.Diagnose(errors.New("disk on fire"), nil)
+	expected = `{"severity":"error","code":"error","message":"disk on fire",` +
+		`"line":null,"column":null,"offset":null,"path":null}`
+	if actual := d.Node().String(); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		input    string
+		opts     internal.ParseOptions
+		expected string
+	}{
+		{`[1, @]`, internal.ParseOptions{}, internal.CodeIllegalToken},
+		{`[tru]`, internal.ParseOptions{}, internal.CodeIllegalToken},
+		{`[1}`, internal.ParseOptions{}, internal.CodeUnbalancedBrackets},
+		{`{"a": [`, internal.ParseOptions{}, internal.CodeUnclosedBrackets},
+		{`["\x"]`, internal.ParseOptions{}, internal.CodeInvalidEscape},
+		{`[01]`, internal.ParseOptions{}, internal.CodeInvalidNumber},
+		{`[[1]]`, internal.ParseOptions{MaxDepth: 1}, internal.CodeTooDeep},
+		{`{"a": 1, "a": 2}`, internal.Strict, internal.CodeDuplicateKey},
+		{`{"a" 1}`, internal.ParseOptions{}, internal.CodeExpectedColon},
+		{`[1 2]`, internal.ParseOptions{}, internal.CodeExpectedComma},
+		{`[1,]`, internal.ParseOptions{}, internal.CodeExpectedValue},
+		{`"a" "b"`, internal.ParseOptions{}, internal.CodeTrailingData},
+		{`/* a`, internal.JSONC, internal.CodeUnterminatedComment},
+		{`['a`, internal.JSON5, internal.CodeUnterminatedString},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := internal.ParseWithOptions(tt.input, tt.opts)
+			var tokenErr *internal.TokenError
+			var syntaxErr *internal.SyntaxError
+			actual := ""
+			if errors.As(err, &tokenErr) {
+				actual = tokenErr.Code
+			} else if errors.As(err, &syntaxErr) {
+				actual = syntaxErr.Code
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %s, got %q for %v", tt.expected, actual, err)
+			}
+			if d := internal.Diagnose(err, nil); d.Code != tt.expected {
+				t.Errorf("expected the diagnostic to have %s, got %s", tt.expected, d.Code)
+			}
+		})
+	}
+
+	// ParseTokens has the same codes as ParseDocument
+	for _, tt := range []struct {
+		input    string
+		opts     internal.ParseOptions
+		expected string
+	}{
+		{`[[1]]`, internal.ParseOptions{MaxDepth: 1}, internal.CodeTooDeep},
+		{`{"a": 1, "a": 2}`, internal.Strict, internal.CodeDuplicateKey},
+	} {
+		l := internal.NewLexer(tt.input)
+		if err := l.ValidateTokens(); err != nil {
+			t.Fatal(err)
+		}
+		_, err := internal.NewParserWithOptions(l.Tokens, tt.opts).ParseTokens()
+		if d := internal.Diagnose(err, nil); d.Code != tt.expected {
+			t.Errorf("expected ParseTokens to fail with %s, got %s for %v", tt.expected, d.Code, err)
+		}
+	}
+
+	doc, err := internal.Parse(`{"a": [1]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = internal.Lookup(doc, "/a/5")
+	var pointerErr *internal.PointerError
+	if !errors.As(err, &pointerErr) || pointerErr.Code != internal.CodePointerNotFound {
+		t.Errorf("expected %s, got %v", internal.CodePointerNotFound, err)
+	}
+}
@@ -0,0 +1,222 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ChangeKind is a string.
+type ChangeKind string
+
+const (
+	// Added is a value that only exists in the second document
+	Added ChangeKind = "added"
+	// Removed is a value that only exists in the first document
+	Removed ChangeKind = "removed"
+	// Changed is a value that exists in both documents but differs
+	Changed ChangeKind = "changed"
+)
+
+// Change is a single difference between two documents.
+type Change struct {
+	Kind ChangeKind
+	// Path points into the first document for removed and changed values and
+	// into the second document for added values
+	Path Pointer
+	// Old is nil for added values
+	Old *Node
+	// New is nil for removed values
+	New *Node
+}
+
+// String formats the change for humans, like "~ /a/0: 1 -> 2".
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s: %v", c.Path, c.New)
+	case Removed:
+		return fmt.Sprintf("- %s: %v", c.Path, c.Old)
+	}
+	return fmt.Sprintf("~ %s: %v -> %v", c.Path, c.Old, c.New)
+}
+
+// Node returns the change as an object with the members kind, path, old and new.
+func (c Change) Node() *Node {
+	n := NewObject()
+	n.Set("kind", NewString(string(c.Kind)))
+	n.Set("path", NewString(c.Path.String()))
+	if c.Old != nil {
+		n.Set("old", c.Old)
+	}
+	if c.New != nil {
+		n.Set("new", c.New)
+	}
+	return n
+}
+
+// DiffOptions changes how documents are compared.
+type DiffOptions struct {
+	// UnorderedArrays compares arrays as sets, ignoring the order of their elements
+	UnorderedArrays bool
+}
+
+// Diff compares both documents semantically, whitespace and the order of object
+// members are ignored and numbers are compared by value.
+func Diff(a, b *Node, opts DiffOptions) []Change {
+	d := differ{opts: opts}
+	d.diff(a, b, Pointer{})
+	return d.changes
+}
+
+type differ struct {
+	opts    DiffOptions
+	changes []Change
+}
+
+func (d *differ) diff(a, b *Node, path Pointer) {
+	if a.Kind != b.Kind {
+		d.changes = append(d.changes, Change{Changed, path, a, b})
+		return
+	}
+
+	switch a.Kind {
+	case ObjectKind:
+		d.diffObjects(a, b, path)
+	case ArrayKind:
+		if d.opts.UnorderedArrays {
+			d.diffSets(a, b, path)
+		} else {
+			d.diffArrays(a, b, path)
+		}
+	default:
+		if !a.Equal(b) {
+			d.changes = append(d.changes, Change{Changed, path, a, b})
+		}
+	}
+}
+
+func (d *differ) diffObjects(a, b *Node, path Pointer) {
+	for _, m := range a.Members {
+		if a.Lookup(m.Name) != m.Value {
+			// only the last of duplicated members counts
+			continue
+		}
+		if v := b.Lookup(m.Name); v != nil {
+			d.diff(m.Value, v, path.Append(m.Name))
+		} else {
+			d.changes = append(d.changes, Change{Removed, path.Append(m.Name), m.Value, nil})
+		}
+	}
+
+	for _, m := range b.Members {
+		if b.Lookup(m.Name) == m.Value && a.Lookup(m.Name) == nil {
+			d.changes = append(d.changes, Change{Added, path.Append(m.Name), nil, m.Value})
+		}
+	}
+}
+
+// diffArrays keeps the longest common subsequence of elements in place, the
+// elements between them are compared pairwise and the rest is added or removed.
+func (d *differ) diffArrays(a, b *Node, path Pointer) {
+	i, j := 0, 0
+	for _, match := range commonElements(a.Elements, b.Elements) {
+		d.diffGap(a, b, i, match[0], j, match[1], path)
+		i, j = match[0]+1, match[1]+1
+	}
+	d.diffGap(a, b, i, len(a.Elements), j, len(b.Elements), path)
+}
+
+func (d *differ) diffGap(a, b *Node, i, iEnd, j, jEnd int, path Pointer) {
+	for ; i < iEnd && j < jEnd; i, j = i+1, j+1 {
+		d.diff(a.Elements[i], b.Elements[j], path.Append(strconv.Itoa(i)))
+	}
+	for ; i < iEnd; i++ {
+		d.changes = append(d.changes, Change{Removed, path.Append(strconv.Itoa(i)), a.Elements[i], nil})
+	}
+	for ; j < jEnd; j++ {
+		d.changes = append(d.changes, Change{Added, path.Append(strconv.Itoa(j)), nil, b.Elements[j]})
+	}
+}
+
+func (d *differ) diffSets(a, b *Node, path Pointer) {
+	matched := make([]bool, len(b.Elements))
+	for i, e := range a.Elements {
+		found := false
+		for j, o := range b.Elements {
+			if !matched[j] && e.Equal(o) {
+				matched[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			d.changes = append(d.changes, Change{Removed, path.Append(strconv.Itoa(i)), e, nil})
+		}
+	}
+
+	for j, o := range b.Elements {
+		if !matched[j] {
+			d.changes = append(d.changes, Change{Added, path.Append(strconv.Itoa(j)), nil, o})
+		}
+	}
+}
+
+// maxLCSCells caps the table of commonElements at 32 MiB, longer arrays that
+// differ in the middle are compared index by index there instead.
+const maxLCSCells = 1 << 22
+
+// commonElements returns the index pairs of the longest common subsequence of
+// equal elements, or only the common prefix and suffix when the rest is too
+// big to compare every pair.
+func commonElements(a, b []*Node) [][2]int {
+	// trim the common prefix and suffix so the table stays small for small edits
+	start := 0
+	for start < len(a) && start < len(b) && a[start].Equal(b[start]) {
+		start++
+	}
+	endA, endB := len(a), len(b)
+	for endA > start && endB > start && a[endA-1].Equal(b[endB-1]) {
+		endA--
+		endB--
+	}
+
+	var matches [][2]int
+	for i := range start {
+		matches = append(matches, [2]int{i, i})
+	}
+
+	n, m := endA-start, endB-start
+	if n*m > maxLCSCells {
+		n, m = 0, 0
+	}
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[start+i].Equal(b[start+j]) {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case a[start+i].Equal(b[start+j]):
+			matches = append(matches, [2]int{start + i, start + j})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	for k := range len(a) - endA {
+		matches = append(matches, [2]int{endA + k, endB + k})
+	}
+	return matches
+}
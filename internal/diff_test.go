@@ -0,0 +1,91 @@
+package internal_test
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name, a, b string
+		unordered  bool
+		expected   []string
+	}{
+		{"Equal with different layout", `{"a": 1, "b": [1, 2]}`, "{\n\"b\": [1, 2.0],\n\"a\": 1e0\n}", false, nil},
+		{"Changed scalar", `{"a": 1}`, `{"a": 2}`, false, []string{`~ /a: 1 -> 2`}},
+		{"Changed kind", `{"a": 1}`, `{"a": "1"}`, false, []string{`~ /a: 1 -> "1"`}},
+		{"Added and removed members", `{"a": 1, "b": 2}`, `{"b": 2, "c": 3}`, false, []string{
+			`- /a: 1`,
+			`+ /c: 3`,
+		}},
+		{"Nested", `{"a": {"b": {"c": true}}}`, `{"a": {"b": {"c": false}}}`, false, []string{
+			`~ /a/b/c: true -> false`,
+		}},
+		{"Array insert", `[1, 2, 3]`, `[1, 4, 2, 3]`, false, []string{`+ /1: 4`}},
+		{"Array remove", `[1, 2, 3]`, `[1, 3]`, false, []string{`- /1: 2`}},
+		{"Array element changed", `[{"a": 1}, 2]`, `[{"a": 5}, 2]`, false, []string{`~ /0/a: 1 -> 5`}},
+		{"Array reorder", `[1, 2, 3]`, `[2, 3, 1]`, false, []string{`- /0: 1`, `+ /2: 1`}},
+		{"Unordered reorder", `[1, 2, 3]`, `[3, 2, 1]`, true, nil},
+		{"Unordered changes", `[1, 2, 2]`, `[2, 4, 1]`, true, []string{`- /2: 2`, `+ /1: 4`}},
+		{"Escaped path", `{"a/b": 1}`, `{"a/b": 2}`, false, []string{`~ /a~1b: 1 -> 2`}},
+		{"Root", `1`, `[1]`, false, []string{`~ : 1 -> [1]`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := internal.Parse(tt.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := internal.Parse(tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var actual []string
+			for _, c := range internal.Diff(a, b, internal.DiffOptions{UnorderedArrays: tt.unordered}) {
+				actual = append(actual, c.String())
+			}
+			if !slices.Equal(actual, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDiff_LongArrays(t *testing.T) {
+	// 3000 by 3000 elements is past the LCS table cap, the middle is compared
+	// index by index
+	a, b := internal.NewArray(), internal.NewArray()
+	for i := range 3000 {
+		a.Elements = append(a.Elements, internal.NewString(strconv.Itoa(i)))
+		b.Elements = append(b.Elements, internal.NewString(strconv.Itoa(i+1)))
+	}
+	a.Elements = append(a.Elements, internal.NewString("end"))
+	b.Elements = append(b.Elements, internal.NewString("end"))
+
+	changes := internal.Diff(a, b, internal.DiffOptions{})
+	if len(changes) != 3000 {
+		t.Fatalf("expected 3000 changes, got %d", len(changes))
+	}
+	if c := changes[0].String(); c != `~ /0: "0" -> "1"` {
+		t.Errorf("expected the first element to change, got %s", c)
+	}
+}
+
+func TestChange_Node(t *testing.T) {
+	a, _ := internal.Parse(`{"a": [1]}`)
+	b, _ := internal.Parse(`{"a": [1, true]}`)
+
+	changes := internal.Diff(a, b, internal.DiffOptions{})
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 change, got %v", changes)
+	}
+	expected := `{"kind":"added","path":"/a/1","new":true}`
+	if actual := changes[0].Node().String(); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
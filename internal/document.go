@@ -0,0 +1,511 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+)
+
+// NodeKind is a string.
+type NodeKind string
+
+const (
+	// ObjectKind is a node holding members
+	ObjectKind NodeKind = "object"
+	// ArrayKind is a node holding elements
+	ArrayKind NodeKind = "array"
+	// StringKind is a string node
+	StringKind NodeKind = "string"
+	// NumberKind is a number node
+	NumberKind NodeKind = "number"
+	// BoolKind is a true or false node
+	BoolKind NodeKind = "boolean"
+	// NullKind is a null node
+	NullKind NodeKind = "null"
+)
+
+// SyntaxError holds the error for when the tokens don't make up a valid document
+type SyntaxError struct {
+	// Code names the kind of error, like CodeExpectedValue
+	Code  string
+	msg   string
+	Token Token
+}
+
+func (s *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at %v, got: %q", s.msg, s.Token.Pos, s.Token.Literal)
+}
+
+// Member is a key value pair inside of an object.
+type Member struct {
+	// Key is the NameString token, it is empty for members that weren't parsed
+	Key   Token
+	Name  string
+	Value *Node
+}
+
+// Node is a value inside of a parsed document.
+type Node struct {
+	Kind NodeKind
+	// Token is the token the value starts with, it carries the source position
+	Token    Token
+	Members  []Member
+	Elements []*Node
+	// Comments are the comments next to the value when comments are allowed:
+	// the ones in front of it, one on the same line right after it and for
+	// objects and arrays the ones before their closing token
+	Comments []Token
+	text     string
+}
+
+// NewObject creates an empty object node.
+func NewObject() *Node {
+	return &Node{Kind: ObjectKind, Token: Token{Type: OpeningCurly, Literal: "{"}}
+}
+
+// NewArray creates an array node holding the given elements.
+func NewArray(elements ...*Node) *Node {
+	return &Node{
+		Kind:     ArrayKind,
+		Token:    Token{Type: OpeningBracket, Literal: "["},
+		Elements: elements,
+	}
+}
+
+// NewString creates a string node.
+func NewString(s string) *Node {
+	q := quote(s)
+	return &Node{
+		Kind:  StringKind,
+		Token: Token{Type: ValueString, Literal: q[1 : len(q)-1]},
+		text:  s,
+	}
+}
+
+// NewNumber creates a number node out of a number literal.
+func NewNumber(literal string) (*Node, error) {
+	if !isNumberLiteral(literal) {
+		return nil, &TokenError{Code: CodeInvalidNumber, msg: "Not a valid number", arg: literal}
+	}
+	return &Node{Kind: NumberKind, Token: Token{Type: Number, Literal: literal}}, nil
+}
+
+// NewBool creates a true or false node.
+func NewBool(b bool) *Node {
+	if b {
+		return &Node{Kind: BoolKind, Token: Token{Type: True, Literal: string(True)}}
+	}
+	return &Node{Kind: BoolKind, Token: Token{Type: False, Literal: string(False)}}
+}
+
+// NewNull creates a null node.
+func NewNull() *Node {
+	return &Node{Kind: NullKind, Token: Token{Type: Null, Literal: string(Null)}}
+}
+
+// Text returns the decoded value of a string node.
+func (n *Node) Text() string {
+	return n.text
+}
+
+// Bool returns the value of a boolean node.
+func (n *Node) Bool() bool {
+	return n.Token.Type == True
+}
+
+// Lookup returns the value of the member with the given name, the last one wins
+// when the name is duplicated.
+func (n *Node) Lookup(name string) *Node {
+	if i := n.memberIndex(name); i >= 0 {
+		return n.Members[i].Value
+	}
+	return nil
+}
+
+// Set replaces the value of the member with the given name or adds it to the end.
+func (n *Node) Set(name string, v *Node) {
+	if i := n.memberIndex(name); i >= 0 {
+		n.Members[i].Value = v
+		return
+	}
+	n.Members = append(n.Members, Member{Name: name, Value: v})
+}
+
+// Delete removes every member with the given name and reports whether there was one.
+func (n *Node) Delete(name string) bool {
+	l := len(n.Members)
+	n.Members = slices.DeleteFunc(n.Members, func(m Member) bool { return m.Name == name })
+	return len(n.Members) != l
+}
+
+// Insert adds the element at the given index, shifting the following elements.
+func (n *Node) Insert(i int, v *Node) {
+	n.Elements = slices.Insert(n.Elements, i, v)
+}
+
+// Remove deletes the element at the given index.
+func (n *Node) Remove(i int) {
+	n.Elements = slices.Delete(n.Elements, i, i+1)
+}
+
+func (n *Node) memberIndex(name string) int {
+	for i := len(n.Members) - 1; i >= 0; i-- {
+		if n.Members[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Clone returns a deep copy of the node.
+func (n *Node) Clone() *Node {
+	c := *n
+	if n.Members != nil {
+		c.Members = make([]Member, len(n.Members))
+		for i, m := range n.Members {
+			c.Members[i] = Member{Key: m.Key, Name: m.Name, Value: m.Value.Clone()}
+		}
+	}
+	if n.Elements != nil {
+		c.Elements = make([]*Node, len(n.Elements))
+		for i, e := range n.Elements {
+			c.Elements[i] = e.Clone()
+		}
+	}
+	return &c
+}
+
+// Equal reports whether both nodes hold the same value, object members are
+// compared regardless of order and numbers are compared by value.
+func (n *Node) Equal(o *Node) bool {
+	if n.Kind != o.Kind {
+		return false
+	}
+
+	switch n.Kind {
+	case ObjectKind:
+		// duplicated names count once with their last value, like Lookup
+		for _, m := range n.Members {
+			v := o.Lookup(m.Name)
+			if v == nil || !n.Lookup(m.Name).Equal(v) {
+				return false
+			}
+		}
+		for _, m := range o.Members {
+			if n.Lookup(m.Name) == nil {
+				return false
+			}
+		}
+		return true
+	case ArrayKind:
+		return slices.EqualFunc(n.Elements, o.Elements, (*Node).Equal)
+	case StringKind:
+		return n.text == o.text
+	case NumberKind:
+		return compareNumbers(n.Token, o.Token) == 0
+	case BoolKind:
+		return n.Token.Type == o.Token.Type
+	}
+	return true
+}
+
+// compareNumbers compares two number tokens by their exact value.
+func compareNumbers(a, b Token) int {
+	da, errA := a.Decimal()
+	db, errB := b.Decimal()
+	if errA != nil || errB != nil {
+		if a.Literal < b.Literal {
+			return -1
+		} else if a.Literal > b.Literal {
+			return 1
+		}
+		return 0
+	}
+
+	return da.Cmp(db)
+}
+
+// ParseDocument builds the document tree out of the tokens.
+func (p *Parser) ParseDocument() (*Node, error) {
+	if len(p.tokens) == 0 {
+		return nil, &SyntaxError{Code: CodeExpectedValue, msg: "Expected a value", Token: Token{Type: Illegal}}
+	}
+
+	b := builder{
+		tokens:         p.tokens,
+		trailingCommas: p.Options.trailingCommas(),
+		warnCommas:     p.Options.WarnTrailingCommas,
+		nonFinite:      p.Options.json5(),
+		maxDepth:       p.Options.MaxDepth,
+		duplicates:     p.Options.DuplicateKeys,
+		warnUnsafe:     p.Options.WarnUnsafeIntegers,
+	}
+	n, err := b.value()
+	p.Warnings = b.warnings
+	if err != nil {
+		return nil, err
+	}
+
+	if t, ok := b.peek(); ok {
+		return nil, &SyntaxError{CodeTrailingData, "Expected the end of the document", t}
+	}
+	n.Comments = append(n.Comments, b.comments...)
+	return n, nil
+}
+
+// Parse lexes and parses the input into a document tree.
+func Parse(input string) (*Node, error) {
+	return ParseWithOptions(input, ParseOptions{})
+}
+
+// ParseWithOptions lexes and parses the input into a document tree using the
+// options, warnings are dropped.
+func ParseWithOptions(input string, opts ParseOptions) (*Node, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	l := NewLexerWithOptions(input, opts)
+	if err := l.ValidateTokens(); err != nil {
+		return nil, err
+	}
+
+	return NewParserWithOptions(l.Tokens, opts).ParseDocument()
+}
+
+type builder struct {
+	tokens []Token
+	pos    int
+	// comments holds the comments waiting for the next value
+	comments []Token
+	// last is the last finished value, end its closing token and prev the
+	// last token next returned
+	last      *Node
+	end, prev Token
+
+	// trailingCommas accepts a comma before } or ], warnCommas adds a
+	// warning for each of them
+	trailingCommas, warnCommas bool
+	warnings                   []Warning
+	// nonFinite accepts the JSON5 numbers Infinity, -Infinity and NaN
+	nonFinite bool
+	// maxDepth limits how deep depth may go when it isn't 0
+	maxDepth, depth int
+	duplicates      DuplicateKeys
+	warnUnsafe      bool
+}
+
+// skipComments moves past comments, attaching a comment on the line the last
+// value ended on to that value and keeping the others for the next one.
+func (b *builder) skipComments() {
+	for b.pos < len(b.tokens) && b.tokens[b.pos].Type == Comment {
+		t := b.tokens[b.pos]
+		after := b.prev.Pos == b.end.Pos || b.prev.Type == Comma
+		if b.last != nil && after && len(b.comments) == 0 && t.Pos.Line == b.end.Pos.Line {
+			b.last.Comments = append(b.last.Comments, t)
+		} else {
+			b.comments = append(b.comments, t)
+		}
+		b.pos++
+	}
+}
+
+// peek returns the next token that isn't a comment without moving past it.
+func (b *builder) peek() (Token, bool) {
+	b.skipComments()
+	if b.pos >= len(b.tokens) {
+		return Token{Type: Illegal}, false
+	}
+	return b.tokens[b.pos], true
+}
+
+func (b *builder) next() (Token, bool) {
+	t, ok := b.peek()
+	if ok {
+		b.pos++
+		b.prev = t
+	}
+	return t, ok
+}
+
+// trailingComma moves past the closing token right after a comma when
+// trailing commas are accepted.
+func (b *builder) trailingComma(closing TokenType) (Token, bool) {
+	t, ok := b.peek()
+	if !ok || t.Type != closing || !b.trailingCommas {
+		return t, false
+	}
+	b.next()
+	if b.warnCommas {
+		b.warnings = append(b.warnings, Warning{CodeTrailingComma, t, "Trailing comma before"})
+	}
+	return t, true
+}
+
+// finish attaches the waiting comments to the value that ends with the token.
+func (b *builder) finish(n *Node, end Token) *Node {
+	n.Comments = append(n.Comments, b.comments...)
+	b.comments = nil
+	b.last, b.end = n, end
+	return n
+}
+
+func (b *builder) eof() *SyntaxError {
+	t := Token{Type: Illegal}
+	for i := len(b.tokens) - 1; i >= 0; i-- {
+		if b.tokens[i].Type != Comment {
+			t = b.tokens[i]
+			break
+		}
+	}
+	return &SyntaxError{CodeUnexpectedEnd, "Unexpected end of the document after", t}
+}
+
+func (b *builder) value() (*Node, error) {
+	t, ok := b.next()
+	if !ok {
+		return nil, b.eof()
+	}
+	comments := b.comments
+	b.comments = nil
+
+	if t.Type == OpeningCurly || t.Type == OpeningBracket {
+		if b.maxDepth > 0 && b.depth >= b.maxDepth {
+			return nil, &SyntaxError{CodeTooDeep, fmt.Sprintf("Nesting is deeper than the limit of %d", b.maxDepth), t}
+		}
+		b.depth++
+		defer func() { b.depth-- }()
+	}
+
+	var n *Node
+	switch t.Type {
+	case OpeningCurly:
+		o, err := b.object(t)
+		if err != nil {
+			return nil, err
+		}
+		n = o
+	case OpeningBracket:
+		a, err := b.array(t)
+		if err != nil {
+			return nil, err
+		}
+		n = a
+	case ValueString, NameString:
+		s, err := unquote(t.Literal)
+		if err != nil {
+			return nil, &SyntaxError{errorCode(err), err.Error(), t}
+		}
+		n = b.finish(&Node{Kind: StringKind, Token: t, text: s}, t)
+	case Number:
+		if !isNumberLiteral(t.Literal) && !(b.nonFinite && isNonFinite(t.Literal)) {
+			return nil, &SyntaxError{CodeInvalidNumber, "Not a valid number", t}
+		}
+		if b.warnUnsafe && t.IsUnsafeInteger() {
+			b.warnings = append(b.warnings, Warning{CodeUnsafeInteger, t, "Integer is bigger than 2^53-1"})
+		}
+		n = b.finish(&Node{Kind: NumberKind, Token: t}, t)
+	case True, False:
+		n = b.finish(&Node{Kind: BoolKind, Token: t}, t)
+	case Null:
+		n = b.finish(&Node{Kind: NullKind, Token: t}, t)
+	default:
+		return nil, &SyntaxError{CodeExpectedValue, "Expected a value", t}
+	}
+
+	if len(comments) > 0 {
+		n.Comments = append(comments, n.Comments...)
+	}
+	return n, nil
+}
+
+func (b *builder) object(start Token) (*Node, error) {
+	n := &Node{Kind: ObjectKind, Token: start, Members: []Member{}}
+	var names map[string]bool
+	if b.duplicates == WarnDuplicates || b.duplicates == RejectDuplicates {
+		names = map[string]bool{}
+	}
+
+	for {
+		key, ok := b.next()
+		if !ok {
+			return nil, b.eof()
+		}
+		if key.Type == ClosingCurly && len(n.Members) == 0 {
+			return b.finish(n, key), nil
+		}
+		if key.Type != NameString && key.Type != ValueString {
+			return nil, &SyntaxError{CodeExpectedMemberName, "Expected a member name", key}
+		}
+		name, err := unquote(key.Literal)
+		if err != nil {
+			return nil, &SyntaxError{errorCode(err), err.Error(), key}
+		}
+		if names != nil {
+			if names[name] && b.duplicates == RejectDuplicates {
+				return nil, &SyntaxError{CodeDuplicateKey, "Duplicate member name", key}
+			} else if names[name] {
+				b.warnings = append(b.warnings, Warning{CodeDuplicateKey, key, "Duplicate member name"})
+			}
+			names[name] = true
+		}
+
+		if t, ok := b.next(); !ok {
+			return nil, b.eof()
+		} else if t.Type != Colon {
+			return nil, &SyntaxError{CodeExpectedColon, "Expected a colon after the member name", t}
+		}
+
+		v, err := b.value()
+		if err != nil {
+			return nil, err
+		}
+		n.Members = append(n.Members, Member{Key: key, Name: name, Value: v})
+
+		t, ok := b.next()
+		if !ok {
+			return nil, b.eof()
+		}
+		switch t.Type {
+		case ClosingCurly:
+			return b.finish(n, t), nil
+		case Comma:
+			if end, ok := b.trailingComma(ClosingCurly); ok {
+				return b.finish(n, end), nil
+			}
+		default:
+			return nil, &SyntaxError{CodeExpectedComma, "Expected a comma or the end of the object", t}
+		}
+	}
+}
+
+func (b *builder) array(start Token) (*Node, error) {
+	n := &Node{Kind: ArrayKind, Token: start, Elements: []*Node{}}
+
+	if t, ok := b.peek(); ok && t.Type == ClosingBracket {
+		b.next()
+		return b.finish(n, t), nil
+	}
+
+	for {
+		v, err := b.value()
+		if err != nil {
+			return nil, err
+		}
+		n.Elements = append(n.Elements, v)
+
+		t, ok := b.next()
+		if !ok {
+			return nil, b.eof()
+		}
+		switch t.Type {
+		case ClosingBracket:
+			return b.finish(n, t), nil
+		case Comma:
+			if end, ok := b.trailingComma(ClosingBracket); ok {
+				return b.finish(n, end), nil
+			}
+		default:
+			return nil, &SyntaxError{CodeExpectedComma, "Expected a comma or the end of the array", t}
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package internal_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func TestParse_Valid(t *testing.T) {
+	tests := []struct {
+		name, input string
+		kind        internal.NodeKind
+	}{
+		{"Empty object", `{}`, internal.ObjectKind},
+		{"Empty array", `[]`, internal.ArrayKind},
+		{"Top level string", `"value"`, internal.StringKind},
+		{"Top level number", `-1.5e3`, internal.NumberKind},
+		{"Top level literal", `true`, internal.BoolKind},
+		{"Nested", `{"a": [1, {"b": null}], "c": "d"}`, internal.ObjectKind},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := internal.Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n.Kind != tt.kind {
+				t.Errorf("expected %v, got %v", tt.kind, n.Kind)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name, input string
+	}{
+		{"Empty input", ``},
+		{"Extra comma", `["extra comma",]`},
+		{"Missing value", `{"a": }`},
+		{"Two values", `{"a": 1 2}`},
+		{"Trailing value", `{} {}`},
+		{"Unclosed object", `{"a": 1`},
+		{"Bad escape", `["\x"]`},
+		{"Bad number", `[1.2.3]`},
+		{"Extra closing curly", `{"a": 1}}`},
+		{"Mismatched closing", `{"a": 1]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if n, err := internal.Parse(tt.input); err == nil {
+				t.Errorf("expected an error, got %v", n)
+			}
+		})
+	}
+}
+
+func TestParse_Strings(t *testing.T) {
+	n, err := internal.Parse(`{"a\/b": "tab\there é \ud83d\ude00 \"q\""}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n.Members[0].Name != "a/b" {
+		t.Errorf("expected %q, got %q", "a/b", n.Members[0].Name)
+	}
+	if v := n.Lookup("a/b").Text(); v != "tab\there é 😀 \"q\"" {
+		t.Errorf("got %q", v)
+	}
+}
+
+func TestNode_Equal(t *testing.T) {
+	tests := []struct {
+		name, a, b string
+		expected   bool
+	}{
+		{"Key order", `{"a": 1, "b": [true, null]}`, `{"b": [true, null], "a": 1}`, true},
+		{"Number value", `[1, 100, 0.5]`, `[1.0, 1e2, 5E-1]`, true},
+		{"Escapes", `"\u0041"`, `"A"`, true},
+		{"Surrogate pair", `{"\ud83d\ude00": 1}`, `{"😀": 1}`, true},
+		{"Lone surrogate", `"\ud83d"`, `"\ufffd"`, true},
+		{"Array order", `[1, 2]`, `[2, 1]`, false},
+		{"Kind", `"1"`, `1`, false},
+		{"Missing member", `{"a": 1}`, `{"a": 1, "b": 2}`, false},
+		{"Duplicate against another member", `{"a": 1, "a": 1}`, `{"a": 1, "b": 1}`, false},
+		{"Other member against a duplicate", `{"a": 1, "b": 1}`, `{"a": 1, "a": 1}`, false},
+		{"Last duplicate wins", `{"a": 1, "a": 2}`, `{"a": 2}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := internal.Parse(tt.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := internal.Parse(tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a.Equal(b) != tt.expected {
+				t.Errorf("expected %v for %s and %s", tt.expected, tt.a, tt.b)
+			}
+		})
+	}
+}
+
+func TestParseDocument_Comments(t *testing.T) {
+	input := `// the config
+{
+  // the name
+  "name": "app", // trailing
+  "ports": [
+    80 /* http */,
+    443
+    // more later
+  ],
+  "db": { /* empty */ }
+}
+/* end */`
+
+	l := internal.NewLexerWithOptions(input, internal.JSONC)
+	if err := l.ValidateTokens(); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := internal.NewParser(l.Tokens).ParseDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := internal.NewParser(l.Tokens).ParseTokens(); !ok {
+		t.Errorf("expected ParseTokens to skip comments, got %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{"", []string{"// the config", "/* end */"}},
+		{"/name", []string{"// the name", "// trailing"}},
+		{"/ports", []string{"// more later"}},
+		{"/ports/0", []string{"/* http */"}},
+		{"/ports/1", nil},
+		{"/db", []string{"/* empty */"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			n, err := internal.Lookup(doc, tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var actual []string
+			for _, c := range n.Comments {
+				actual = append(actual, c.Literal)
+			}
+			if !slices.Equal(actual, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"strings"
+)
+
+// Marshal returns the compact json text of the node.
+func Marshal(n *Node) []byte {
+	var b strings.Builder
+	encode(&b, n, "", "", "")
+	return []byte(b.String())
+}
+
+// MarshalIndent returns the json text of the node, every member and element
+// starts on a new line beginning with prefix followed by one indent per level.
+func MarshalIndent(n *Node, prefix, indent string) []byte {
+	var b strings.Builder
+	encode(&b, n, "\n"+prefix, indent, "")
+	return []byte(b.String())
+}
+
+// String returns the compact json text of the node.
+func (n *Node) String() string {
+	return string(Marshal(n))
+}
+
+func encode(b *strings.Builder, n *Node, newline, indent, depth string) {
+	switch n.Kind {
+	case ObjectKind:
+		if len(n.Members) == 0 {
+			b.WriteString("{}")
+			return
+		}
+
+		b.WriteByte('{')
+		for i, m := range n.Members {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeNewline(b, newline, depth+indent)
+			b.WriteString(quote(m.Name))
+			b.WriteByte(':')
+			if newline != "" {
+				b.WriteByte(' ')
+			}
+			encode(b, m.Value, newline, indent, depth+indent)
+		}
+		writeNewline(b, newline, depth)
+		b.WriteByte('}')
+	case ArrayKind:
+		if len(n.Elements) == 0 {
+			b.WriteString("[]")
+			return
+		}
+
+		b.WriteByte('[')
+		for i, e := range n.Elements {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeNewline(b, newline, depth+indent)
+			encode(b, e, newline, indent, depth+indent)
+		}
+		writeNewline(b, newline, depth)
+		b.WriteByte(']')
+	case StringKind:
+		b.WriteString(quote(n.text))
+	case NumberKind:
+		// JSON has no Infinity or NaN, JSON.stringify writes them as null
+		if isNonFinite(n.Token.Literal) {
+			b.WriteString("null")
+		} else {
+			b.WriteString(n.Token.Literal)
+		}
+	default:
+		b.WriteString(n.Token.Literal)
+	}
+}
+
+func writeNewline(b *strings.Builder, newline, depth string) {
+	if newline == "" {
+		return
+	}
+	b.WriteString(newline)
+	b.WriteString(depth)
+}
@@ -0,0 +1,32 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name, input, compact, indented string
+	}{
+		{"Scalar", `"aA\n"`, `"aA\n"`, `"aA\n"`},
+		{"Empty containers", `{"a": {}, "b": []}`, `{"a":{},"b":[]}`, "{\n  \"a\": {},\n  \"b\": []\n}"},
+		{"Nested", `[1, {"k": null}]`, `[1,{"k":null}]`, "[\n  1,\n  {\n    \"k\": null\n  }\n]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := internal.Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := string(internal.Marshal(n)); actual != tt.compact {
+				t.Errorf("expected %s, got %s", tt.compact, actual)
+			}
+			if actual := string(internal.MarshalIndent(n, "", "  ")); actual != tt.indented {
+				t.Errorf("expected %s, got %s", tt.indented, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// unquote decodes the escape sequences of a string literal, the literal is
+// expected without its surrounding quotes.
+func unquote(lit string) (string, error) {
+	if !needsUnquote(lit) {
+		return lit, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(lit); i++ {
+		c := lit[i]
+		if c < 0x20 {
+			return "", &TokenError{Code: CodeInvalidString, msg: "Control character in string", arg: strconv.Quote(string(c))}
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		if i >= len(lit) {
+			return "", &TokenError{Code: CodeInvalidEscape, msg: "Unfinished escape sequence in", arg: lit}
+		}
+
+		switch lit[i] {
+		case '"', '\\', '/':
+			b.WriteByte(lit[i])
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			r, ok := readHex(lit[i+1:])
+			if !ok {
+				return "", &TokenError{Code: CodeInvalidEscape, msg: "Invalid unicode escape in", arg: lit}
+			}
+			i += 4
+
+			if utf16.IsSurrogate(r) {
+				high := r
+				r = utf8.RuneError
+				if strings.HasPrefix(lit[i+1:], `\u`) {
+					if r2, ok := readHex(lit[i+3:]); ok && utf16.DecodeRune(high, r2) != utf8.RuneError {
+						r = utf16.DecodeRune(high, r2)
+						i += 6
+					}
+				}
+			}
+			b.WriteRune(r)
+		default:
+			return "", &TokenError{Code: CodeInvalidEscape, msg: "Invalid escape character", arg: string(lit[i])}
+		}
+	}
+
+	return b.String(), nil
+}
+
+func needsUnquote(lit string) bool {
+	for i := 0; i < len(lit); i++ {
+		if lit[i] == '\\' || lit[i] < 0x20 {
+			return true
+		}
+	}
+	return false
+}
+
+func readHex(s string) (rune, bool) {
+	if len(s) < 4 {
+		return 0, false
+	}
+
+	v, err := strconv.ParseUint(s[:4], 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	return rune(v), true
+}
+
+// quote returns the string as a json string literal, quotes included.
+func quote(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				b.WriteString(`\u00`)
+				b.WriteByte("0123456789abcdef"[c>>4])
+				b.WriteByte("0123456789abcdef"[c&0xf])
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
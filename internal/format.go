@@ -0,0 +1,244 @@
+package internal
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FormatOptions changes how Format lays out a document.
+type FormatOptions struct {
+	// ParseOptions says which input is accepted, trailing commas are only
+	// dropped when they are allowed
+	ParseOptions
+	// Indent is the number of spaces per level, it is ignored with Tabs
+	Indent int
+	// Tabs indents with one tab per level
+	Tabs bool
+	// SortKeys orders the members of objects by name
+	SortKeys bool
+	// ArrayWidth puts arrays without objects or arrays inside on one line when
+	// that fits in this many bytes, 0 puts every element on its own line
+	ArrayWidth int
+	// FinalNewline ends the output with a newline
+	FinalNewline bool
+}
+
+// Format lays out the tokens of a document in a single pass over them.
+// Strings and numbers are written as they were spelled in the input, comments
+// and the trailing commas opts allows are left out. The tokens of a JSON5
+// lexer come out as JSON, with null for Infinity and NaN like JSON.stringify.
+func Format(tokens []Token, opts FormatOptions) ([]byte, error) {
+	if len(tokens) == 0 {
+		return nil, &SyntaxError{Code: CodeExpectedValue, msg: "Expected a value", Token: Token{Type: Illegal}}
+	}
+
+	f := formatter{builder: builder{tokens: tokens, trailingCommas: opts.trailingCommas()}, opts: opts, colon: ": "}
+	if opts.Tabs {
+		f.indent = "\t"
+	} else if opts.Indent < 0 {
+		return nil, &OptionsError{"Indent can't be negative, got", strconv.Itoa(opts.Indent)}
+	} else {
+		f.indent = strings.Repeat(" ", opts.Indent)
+	}
+
+	var b strings.Builder
+	if err := f.value(&b, "\n"); err != nil {
+		return nil, err
+	}
+	if t, ok := f.peek(); ok {
+		return nil, &SyntaxError{CodeTrailingData, "Expected the end of the document", t}
+	}
+
+	if opts.FinalNewline {
+		b.WriteByte('\n')
+	}
+	return []byte(b.String()), nil
+}
+
+type formatter struct {
+	builder
+	opts   FormatOptions
+	minify MinifyOptions
+	indent string
+	colon  string
+}
+
+// value writes the next value, newline is the line break plus the
+// indentation of the line the value starts on.
+func (f *formatter) value(b *strings.Builder, newline string) error {
+	t, ok := f.next()
+	if !ok {
+		return f.eof()
+	}
+
+	switch t.Type {
+	case OpeningCurly:
+		return f.object(b, newline)
+	case OpeningBracket:
+		return f.array(b, newline)
+	case ValueString, NameString:
+		s, err := f.string(t)
+		if err != nil {
+			return err
+		}
+		b.WriteString(s)
+	case Number:
+		if isNonFinite(t.Literal) {
+			b.WriteString("null")
+			return nil
+		}
+		if !isNumberLiteral(t.Literal) {
+			return &SyntaxError{CodeInvalidNumber, "Not a valid number", t}
+		}
+		if f.minify.Numbers {
+			b.WriteString(normalizeNumber(t))
+		} else {
+			b.WriteString(t.Literal)
+		}
+	case True, False, Null:
+		b.WriteString(t.Literal)
+	default:
+		return &SyntaxError{CodeExpectedValue, "Expected a value", t}
+	}
+	return nil
+}
+
+// string returns the string literal with its quotes.
+func (f *formatter) string(t Token) (string, error) {
+	s, err := unquote(t.Literal)
+	if err != nil {
+		return "", &SyntaxError{errorCode(err), err.Error(), t}
+	}
+	// surrogate escapes are kept as written, unquote can't keep lone ones
+	if f.minify.Strings && !strings.Contains(strings.ToLower(t.Literal), `\ud`) {
+		return quote(s), nil
+	}
+	return `"` + t.Literal + `"`, nil
+}
+
+func (f *formatter) object(b *strings.Builder, newline string) error {
+	type member struct {
+		name, text string
+	}
+	var members []member
+
+	inner := newline + f.indent
+	for {
+		key, ok := f.next()
+		if !ok {
+			return f.eof()
+		}
+		if key.Type == ClosingCurly && len(members) == 0 {
+			b.WriteString("{}")
+			return nil
+		}
+		if key.Type != NameString && key.Type != ValueString {
+			return &SyntaxError{CodeExpectedMemberName, "Expected a member name", key}
+		}
+		name, err := unquote(key.Literal)
+		if err != nil {
+			return &SyntaxError{errorCode(err), err.Error(), key}
+		}
+		literal, err := f.string(key)
+		if err != nil {
+			return err
+		}
+
+		if t, ok := f.next(); !ok {
+			return f.eof()
+		} else if t.Type != Colon {
+			return &SyntaxError{CodeExpectedColon, "Expected a colon after the member name", t}
+		}
+
+		var mb strings.Builder
+		mb.WriteString(literal + f.colon)
+		if err := f.value(&mb, inner); err != nil {
+			return err
+		}
+		members = append(members, member{name, mb.String()})
+
+		t, ok := f.next()
+		if !ok {
+			return f.eof()
+		}
+		if t.Type == ClosingCurly {
+			break
+		}
+		if t.Type != Comma {
+			return &SyntaxError{CodeExpectedComma, "Expected a comma or the end of the object", t}
+		}
+		if _, ok := f.trailingComma(ClosingCurly); ok {
+			break
+		}
+	}
+
+	if f.opts.SortKeys {
+		slices.SortStableFunc(members, func(a, b member) int { return strings.Compare(a.name, b.name) })
+	}
+
+	b.WriteByte('{')
+	for i, m := range members {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(inner)
+		b.WriteString(m.text)
+	}
+	b.WriteString(newline)
+	b.WriteByte('}')
+	return nil
+}
+
+func (f *formatter) array(b *strings.Builder, newline string) error {
+	if t, ok := f.peek(); ok && t.Type == ClosingBracket {
+		f.next()
+		b.WriteString("[]")
+		return nil
+	}
+
+	var elements []string
+	flat := true
+	inner := newline + f.indent
+	for {
+		if t, _ := f.peek(); t.Type == OpeningCurly || t.Type == OpeningBracket {
+			flat = false
+		}
+		var eb strings.Builder
+		if err := f.value(&eb, inner); err != nil {
+			return err
+		}
+		elements = append(elements, eb.String())
+
+		t, ok := f.next()
+		if !ok {
+			return f.eof()
+		}
+		if t.Type == ClosingBracket {
+			break
+		}
+		if t.Type != Comma {
+			return &SyntaxError{CodeExpectedComma, "Expected a comma or the end of the array", t}
+		}
+		if _, ok := f.trailingComma(ClosingBracket); ok {
+			break
+		}
+	}
+
+	if line := "[" + strings.Join(elements, ", ") + "]"; flat && len(line) <= f.opts.ArrayWidth {
+		b.WriteString(line)
+		return nil
+	}
+
+	b.WriteByte('[')
+	for i, e := range elements {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(inner)
+		b.WriteString(e)
+	}
+	b.WriteString(newline)
+	b.WriteByte(']')
+	return nil
+}
@@ -0,0 +1,89 @@
+package internal_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name, input string
+		opts        internal.FormatOptions
+		expected    string
+	}{
+		{"Scalar", ` 1.50 `, internal.FormatOptions{Indent: 2}, `1.50`},
+		{"Empty containers", `{"a":{},"b":[ ]}`, internal.FormatOptions{Indent: 2},
+			"{\n  \"a\": {},\n  \"b\": []\n}"},
+		{"Spaces", `{"a":[1,{"b":"\u00e9"}],"c":null}`, internal.FormatOptions{Indent: 4},
+			"{\n    \"a\": [\n        1,\n        {\n            \"b\": \"\\u00e9\"\n        }\n    ],\n    \"c\": null\n}"},
+		{"Tabs", `{"a":[true]}`, internal.FormatOptions{Indent: 4, Tabs: true},
+			"{\n\t\"a\": [\n\t\ttrue\n\t]\n}"},
+		{"Sorted keys", `{"b":1,"a":{"d":2,"c":3},"\u0041":0}`, internal.FormatOptions{Indent: 1, SortKeys: true},
+			"{\n \"\\u0041\": 0,\n \"a\": {\n  \"c\": 3,\n  \"d\": 2\n },\n \"b\": 1\n}"},
+		{"Short arrays", `[[1,2,3],[1,2,3,4,5,6],[{"a":1}]]`, internal.FormatOptions{Indent: 2, ArrayWidth: 10},
+			"[\n  [1, 2, 3],\n  [\n    1,\n    2,\n    3,\n    4,\n    5,\n    6\n  ],\n  [\n    {\n      \"a\": 1\n    }\n  ]\n]"},
+		{"Final newline", `[]`, internal.FormatOptions{FinalNewline: true}, "[]\n"},
+		{"Trailing commas", `{"a":[1,2,],}`,
+			internal.FormatOptions{ParseOptions: internal.ParseOptions{AllowTrailingCommas: true}, Indent: 2, ArrayWidth: 10},
+			"{\n  \"a\": [1, 2]\n}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := internal.NewLexer(tt.input)
+			if err := l.ValidateTokens(); err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := internal.Format(l.Tokens, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFormat_Invalid(t *testing.T) {
+	lenient := internal.ParseOptions{AllowTrailingCommas: true}
+	tests := []struct {
+		name, input string
+		opts        internal.ParseOptions
+	}{
+		{"Empty", ``, internal.ParseOptions{}},
+		{"Missing colon", `{"a" 1}`, internal.ParseOptions{}},
+		{"Missing value", `[1,]`, internal.ParseOptions{}},
+		{"Two trailing commas", `[1,,]`, lenient},
+		{"Only a comma", `{,}`, lenient},
+		{"Bad number", `[01]`, internal.ParseOptions{}},
+		{"Bad escape", `["\x"]`, internal.ParseOptions{}},
+		{"Two values", `1 2`, internal.ParseOptions{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := internal.NewLexerWithOptions(tt.input, tt.opts)
+			if err := l.ValidateTokens(); err != nil {
+				return
+			}
+			if _, err := internal.Format(l.Tokens, internal.FormatOptions{ParseOptions: tt.opts, Indent: 2}); err == nil {
+				t.Errorf("expected an error for %s", tt.input)
+			}
+		})
+	}
+}
+
+func TestFormat_NegativeIndent(t *testing.T) {
+	l := internal.NewLexer(`[1]`)
+	if err := l.ValidateTokens(); err != nil {
+		t.Fatal(err)
+	}
+	var optsErr *internal.OptionsError
+	if _, err := internal.Format(l.Tokens, internal.FormatOptions{Indent: -1}); !errors.As(err, &optsErr) {
+		t.Errorf("expected an OptionsError, got %v", err)
+	}
+}
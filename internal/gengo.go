@@ -0,0 +1,251 @@
+package internal
+
+import (
+	"fmt"
+	"go/format"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GoError holds the error for when the samples can't be turned into Go types
+type GoError struct {
+	msg, arg string
+}
+
+func (g *GoError) Error() string {
+	return fmt.Sprintf("%s %q", g.msg, g.arg)
+}
+
+// GoOptions changes the generated Go code.
+type GoOptions struct {
+	// Package is the package clause, "main" when empty
+	Package string
+	// Name is the name of the top level type, "Root" when empty
+	Name string
+}
+
+// initialisms are the words Go spells in all capitals.
+var initialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true,
+	"EOF": true, "GUID": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "LHS": true, "QPS": true, "RAM": true, "RHS": true,
+	"RPC": true, "SLA": true, "SMTP": true, "SQL": true, "SSH": true, "TCP": true,
+	"TLS": true, "TTL": true, "UDP": true, "UI": true, "UID": true, "URI": true,
+	"URL": true, "UTF8": true, "UUID": true, "VM": true, "XML": true,
+}
+
+// GenerateGo returns formatted Go type definitions with json tags that all
+// the samples decode into. Members missing from some samples become optional
+// fields, numbers are int64 when every literal was a plain integer and
+// float64 otherwise, and nested objects get their own struct named after
+// the member holding them. Member names that encoding/json can't put in a
+// tag, like "" or ones with a comma or a quote, are an error.
+func GenerateGo(samples []*Node, opts GoOptions) ([]byte, error) {
+	s := newShape()
+	for _, n := range samples {
+		s.add(n)
+	}
+
+	pkg, name := opts.Package, opts.Name
+	if pkg == "" {
+		pkg = "main"
+	}
+	if name == "" {
+		name = "Root"
+	}
+
+	g := goGenerator{names: map[string]bool{}}
+	fmt.Fprintf(&g.b, "package %s\n", pkg)
+	if typ := g.typeOf(s, name, ""); typ != name {
+		fmt.Fprintf(&g.b, "\ntype %s %s\n", g.unique(name, ""), typ)
+	}
+	for i := 0; i < len(g.structs); i++ {
+		if err := g.writeStruct(g.structs[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return format.Source([]byte(g.b.String()))
+}
+
+type goStruct struct {
+	name  string
+	shape *shape
+}
+
+type goGenerator struct {
+	b       strings.Builder
+	names   map[string]bool
+	structs []goStruct
+}
+
+// typeOf returns the Go type of the values in s, objects are queued as
+// structs with a name based on the member holding them.
+func (g *goGenerator) typeOf(s *shape, name, parent string) string {
+	kinds := slices.DeleteFunc(slices.Clone(s.kinds), func(k NodeKind) bool { return k == NullKind })
+	if len(kinds) != 1 {
+		return "any"
+	}
+
+	switch kinds[0] {
+	case NumberKind:
+		if s.int64s {
+			return "int64"
+		}
+		return "float64"
+	case StringKind:
+		return "string"
+	case BoolKind:
+		return "bool"
+	case ObjectKind:
+		if len(s.properties) == 0 {
+			return "map[string]any"
+		}
+		name = g.unique(name, parent)
+		g.structs = append(g.structs, goStruct{name, s})
+		return name
+	default:
+		if s.items == nil || s.items.count == 0 {
+			return "[]any"
+		}
+		return "[]" + g.typeOf(s.items, singular(name), parent)
+	}
+}
+
+// unique reserves a type name, when it is taken the parent is used as a
+// prefix before falling back to a number.
+func (g *goGenerator) unique(name, parent string) string {
+	candidate := name
+	if g.names[candidate] && parent != "" {
+		candidate = parent + name
+	}
+	for i := 2; g.names[candidate]; i++ {
+		candidate = name + strconv.Itoa(i)
+	}
+	g.names[candidate] = true
+	return candidate
+}
+
+func (g *goGenerator) writeStruct(st goStruct) error {
+	fmt.Fprintf(&g.b, "\ntype %s struct {\n", st.name)
+
+	fields := map[string]bool{}
+	for _, key := range st.shape.properties {
+		member := st.shape.members[key]
+
+		field := goName(key)
+		for i := 2; fields[field]; i++ {
+			field = goName(key) + strconv.Itoa(i)
+		}
+		fields[field] = true
+
+		typ := g.typeOf(member, field, st.name)
+		optional := member.count < st.shape.objects
+		nullable := slices.Contains(member.kinds, NullKind)
+		if (optional || nullable) && typ != "any" && !strings.HasPrefix(typ, "[]") && !strings.HasPrefix(typ, "map[") {
+			typ = "*" + typ
+		}
+
+		tag, err := structTag(key, optional)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&g.b, "%s %s %s\n", field, typ, tag)
+	}
+
+	g.b.WriteString("}\n")
+	return nil
+}
+
+// structTag returns the json struct tag for the member name as a Go string
+// literal. "-" needs a comma after it, or encoding/json skips the field.
+func structTag(name string, omitempty bool) (string, error) {
+	if !validTagName(name) {
+		return "", &GoError{"encoding/json can't name a field after the member", name}
+	}
+
+	tag := name
+	if omitempty {
+		tag += ",omitempty"
+	} else if name == "-" {
+		tag += ","
+	}
+	return "`json:" + strconv.Quote(tag) + "`", nil
+}
+
+// validTagName reports whether encoding/json accepts the name in a tag, it
+// falls back to the field name for the others.
+func validTagName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", r) && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// goName turns a member name into an exported Go identifier, "user_id" and
+// "userId" both become "UserID".
+func goName(key string) string {
+	var words []string
+	var word []rune
+	var prev rune
+	for _, r := range key {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			words = append(words, string(word))
+			word = nil
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			words = append(words, string(word))
+			word = []rune{r}
+		default:
+			word = append(word, r)
+		}
+		prev = r
+	}
+	words = append(words, string(word))
+
+	var b strings.Builder
+	for _, w := range words {
+		if w == "" {
+			continue
+		}
+		if upper := strings.ToUpper(w); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+
+	name := b.String()
+	switch {
+	case name == "":
+		return "Field"
+	case unicode.IsDigit([]rune(name)[0]):
+		return "Field" + name
+	case !unicode.IsUpper([]rune(name)[0]):
+		return "X" + name
+	}
+	return name
+}
+
+// singular names the elements of an array from the name of the array.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"),
+		strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") && len(name) > 1:
+		return strings.TrimSuffix(name, "s")
+	}
+	return name + "Item"
+}
@@ -0,0 +1,141 @@
+package internal_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func TestGenerateGo(t *testing.T) {
+	tests := []struct {
+		name     string
+		samples  []string
+		opts     internal.GoOptions
+		expected string
+	}{
+		{
+			"Scalars",
+			[]string{`{"id": 1, "price": 2.5, "name": "a", "ok": true, "other": null}`},
+			internal.GoOptions{},
+			"package main\n\ntype Root struct {\n" +
+				"\tID    int64   `json:\"id\"`\n" +
+				"\tPrice float64 `json:\"price\"`\n" +
+				"\tName  string  `json:\"name\"`\n" +
+				"\tOk    bool    `json:\"ok\"`\n" +
+				"\tOther any     `json:\"other\"`\n" +
+				"}\n",
+		},
+		{
+			"Merged samples",
+			[]string{`{"count": 1, "user_id": 7, "tag": "a"}`, `{"count": 1.5, "user_id": 8, "tag": null, "extra": "x"}`},
+			internal.GoOptions{Package: "models", Name: "Event"},
+			"package models\n\ntype Event struct {\n" +
+				"\tCount  float64 `json:\"count\"`\n" +
+				"\tUserID int64   `json:\"user_id\"`\n" +
+				"\tTag    *string `json:\"tag\"`\n" +
+				"\tExtra  *string `json:\"extra,omitempty\"`\n" +
+				"}\n",
+		},
+		{
+			"Exponents are floats",
+			[]string{`{"n": 1e3, "m": 9223372036854775808}`},
+			internal.GoOptions{},
+			"package main\n\ntype Root struct {\n" +
+				"\tN float64 `json:\"n\"`\n" +
+				"\tM float64 `json:\"m\"`\n" +
+				"}\n",
+		},
+		{
+			"Nested structs",
+			[]string{`{"address": {"city": "x"}, "categories": [{"name": "a"}], "meta": {}, "matrix": [[1]]}`},
+			internal.GoOptions{},
+			"package main\n\ntype Root struct {\n" +
+				"\tAddress    Address        `json:\"address\"`\n" +
+				"\tCategories []Category     `json:\"categories\"`\n" +
+				"\tMeta       map[string]any `json:\"meta\"`\n" +
+				"\tMatrix     [][]int64      `json:\"matrix\"`\n" +
+				"}\n\ntype Address struct {\n" +
+				"\tCity string `json:\"city\"`\n" +
+				"}\n\ntype Category struct {\n" +
+				"\tName string `json:\"name\"`\n" +
+				"}\n",
+		},
+		{
+			"Name collisions",
+			[]string{`{"item": {"a": 1}, "box": {"item": {"b": true}}, "Item": 2}`},
+			internal.GoOptions{},
+			"package main\n\ntype Root struct {\n" +
+				"\tItem  Item  `json:\"item\"`\n" +
+				"\tBox   Box   `json:\"box\"`\n" +
+				"\tItem2 int64 `json:\"Item\"`\n" +
+				"}\n\ntype Item struct {\n" +
+				"\tA int64 `json:\"a\"`\n" +
+				"}\n\ntype Box struct {\n" +
+				"\tItem BoxItem `json:\"item\"`\n" +
+				"}\n\ntype BoxItem struct {\n" +
+				"\tB bool `json:\"b\"`\n" +
+				"}\n",
+		},
+		{
+			"Top level array",
+			[]string{`[{"first-name": "a", "2fa": false}]`, `[]`},
+			internal.GoOptions{},
+			"package main\n\ntype Root []RootItem\n\ntype RootItem struct {\n" +
+				"\tFirstName string `json:\"first-name\"`\n" +
+				"\tField2fa  bool   `json:\"2fa\"`\n" +
+				"}\n",
+		},
+		{
+			"Dash member",
+			[]string{`{"-": 1, "a": 2}`, `{"-": 1}`},
+			internal.GoOptions{},
+			"package main\n\ntype Root struct {\n" +
+				"\tField int64  `json:\"-,\"`\n" +
+				"\tA     *int64 `json:\"a,omitempty\"`\n" +
+				"}\n",
+		},
+		{
+			"Mixed types",
+			[]string{`{"v": 1}`, `{"v": "a"}`},
+			internal.GoOptions{},
+			"package main\n\ntype Root struct {\n" +
+				"\tV any `json:\"v\"`\n" +
+				"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var samples []*internal.Node
+			for _, s := range tt.samples {
+				doc, err := internal.Parse(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				samples = append(samples, doc)
+			}
+
+			src, err := internal.GenerateGo(samples, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := string(src); actual != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGenerateGo_InvalidNames(t *testing.T) {
+	for _, input := range []string{`{"": 1}`, `{"a,b": 1}`, `{"a\"b": 1}`, "{\"a`b\": 1}"} {
+		doc, err := internal.Parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var goErr *internal.GoError
+		if _, err := internal.GenerateGo([]*internal.Node{doc}, internal.GoOptions{}); !errors.As(err, &goErr) {
+			t.Errorf("expected a GoError for %s, got %v", input, err)
+		}
+	}
+}
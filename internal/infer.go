@@ -0,0 +1,166 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+)
+
+// InferOptions changes how schemas are inferred.
+type InferOptions struct {
+	// MaxEnum is the most distinct strings a value can have and still be
+	// described as an enum, 0 never infers enums
+	MaxEnum int
+}
+
+// InferSchema returns a JSON Schema (draft 2020-12) that describes every
+// sample: the types, which object members are required, enums for strings
+// with few distinct values and the range of numbers.
+func InferSchema(samples []*Node, opts InferOptions) *Node {
+	s := newShape()
+	for _, n := range samples {
+		s.add(n)
+	}
+
+	schema := NewObject()
+	schema.Set("$schema", NewString("https://json-schema.org/draft/2020-12/schema"))
+	for _, m := range s.schema(opts).Members {
+		schema.Set(m.Name, m.Value)
+	}
+	return schema
+}
+
+// shape collects everything seen at a single path of the samples.
+type shape struct {
+	count int
+	kinds []NodeKind
+
+	integers bool
+	// int64s is set while every number literal is an integer that fits in
+	// an int64 and is written without a fraction or exponent
+	int64s   bool
+	min, max *Node
+
+	texts    int
+	strings  []string
+	distinct map[string]bool
+
+	objects    int
+	properties []string
+	members    map[string]*shape
+
+	items *shape
+}
+
+func newShape() *shape {
+	return &shape{integers: true, int64s: true, distinct: map[string]bool{}, members: map[string]*shape{}}
+}
+
+func (s *shape) add(n *Node) {
+	s.count++
+	if !slices.Contains(s.kinds, n.Kind) {
+		s.kinds = append(s.kinds, n.Kind)
+	}
+
+	switch n.Kind {
+	case NumberKind:
+		if d, err := n.Token.Decimal(); err != nil || !d.IsInteger() {
+			s.integers = false
+		}
+		if _, err := n.Token.Int64(); err != nil || strings.ContainsAny(n.Token.Literal, ".eE") {
+			s.int64s = false
+		}
+		if s.min == nil || compareNumbers(n.Token, s.min.Token) < 0 {
+			s.min = n
+		}
+		if s.max == nil || compareNumbers(n.Token, s.max.Token) > 0 {
+			s.max = n
+		}
+	case StringKind:
+		s.texts++
+		if !s.distinct[n.text] {
+			s.distinct[n.text] = true
+			s.strings = append(s.strings, n.text)
+		}
+	case ObjectKind:
+		s.objects++
+		seen := map[string]bool{}
+		for _, m := range n.Members {
+			if seen[m.Name] {
+				continue
+			}
+			seen[m.Name] = true
+
+			member, ok := s.members[m.Name]
+			if !ok {
+				member = newShape()
+				s.members[m.Name] = member
+				s.properties = append(s.properties, m.Name)
+			}
+			member.add(n.Lookup(m.Name))
+		}
+	case ArrayKind:
+		if s.items == nil {
+			s.items = newShape()
+		}
+		for _, e := range n.Elements {
+			s.items.add(e)
+		}
+	}
+}
+
+func (s *shape) schema(opts InferOptions) *Node {
+	schema := NewObject()
+
+	var types []*Node
+	for _, k := range s.kinds {
+		if k == NumberKind && s.integers {
+			types = append(types, NewString("integer"))
+		} else {
+			types = append(types, NewString(string(k)))
+		}
+	}
+	switch len(types) {
+	case 0:
+		return schema
+	case 1:
+		schema.Set("type", types[0])
+	default:
+		schema.Set("type", NewArray(types...))
+	}
+
+	for _, k := range s.kinds {
+		switch k {
+		case NumberKind:
+			schema.Set("minimum", s.min)
+			schema.Set("maximum", s.max)
+		case StringKind:
+			// only a few values that repeat look like an enum rather than free text
+			if len(s.strings) <= opts.MaxEnum && s.texts > len(s.strings) {
+				enum := NewArray()
+				for _, str := range s.strings {
+					enum.Elements = append(enum.Elements, NewString(str))
+				}
+				schema.Set("enum", enum)
+			}
+		case ObjectKind:
+			properties := NewObject()
+			required := NewArray()
+			for _, name := range s.properties {
+				member := s.members[name]
+				properties.Set(name, member.schema(opts))
+				if member.count == s.objects {
+					required.Elements = append(required.Elements, NewString(name))
+				}
+			}
+			schema.Set("properties", properties)
+			if len(required.Elements) > 0 {
+				schema.Set("required", required)
+			}
+		case ArrayKind:
+			if s.items.count > 0 {
+				schema.Set("items", s.items.schema(opts))
+			}
+		}
+	}
+	return schema
+}
@@ -0,0 +1,63 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func TestInferSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		samples  []string
+		maxEnum  int
+		expected string
+	}{
+		{"Scalar", []string{`1`}, 0, `{"type":"integer","minimum":1,"maximum":1}`},
+		{"Number range", []string{`3`, `-1.5`, `10`}, 0, `{"type":"number","minimum":-1.5,"maximum":10}`},
+		{"Mixed types", []string{`"a"`, `null`, `true`}, 0, `{"type":["string","null","boolean"]}`},
+		{"Required and optional", []string{`{"id": 1, "name": "a"}`, `{"id": 2}`}, 0,
+			`{"type":"object","properties":{"id":{"type":"integer","minimum":1,"maximum":2},"name":{"type":"string"}},"required":["id"]}`},
+		{"Enum", []string{`{"s": "on"}`, `{"s": "off"}`, `{"s": "on"}`}, 2,
+			`{"type":"object","properties":{"s":{"type":"string","enum":["on","off"]}},"required":["s"]}`},
+		{"Too many strings for an enum", []string{`"a"`, `"b"`, `"c"`, `"a"`}, 2, `{"type":"string"}`},
+		{"Every string distinct", []string{`"a"`, `"b"`}, 5, `{"type":"string"}`},
+		{"Array items", []string{`[1, "x"]`, `[]`, `[2.5]`}, 0,
+			`{"type":"array","items":{"type":["number","string"],"minimum":1,"maximum":2.5}}`},
+		{"Empty array", []string{`[]`}, 0, `{"type":"array"}`},
+		{"Nested objects", []string{`{"a": {"b": [{"c": null}]}}`}, 0,
+			`{"type":"object","properties":{"a":{"type":"object","properties":{"b":{"type":"array","items":{"type":"object","properties":{"c":{"type":"null"}},"required":["c"]}}},"required":["b"]}},"required":["a"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var samples []*internal.Node
+			for _, s := range tt.samples {
+				doc, err := internal.Parse(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				samples = append(samples, doc)
+			}
+
+			schema := internal.InferSchema(samples, internal.InferOptions{MaxEnum: tt.maxEnum})
+			if s := schema.Lookup("$schema"); s == nil || s.Text() != "https://json-schema.org/draft/2020-12/schema" {
+				t.Errorf("expected a draft 2020-12 $schema, got %v", s)
+			}
+			schema.Delete("$schema")
+			if actual := schema.String(); actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+
+			compiled, err := internal.CompileSchema(schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, s := range samples {
+				if errs := compiled.Validate(s); len(errs) > 0 {
+					t.Errorf("sample %d doesn't match the inferred schema: %v", i, errs)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package internal
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ParseJSON5 lexes and parses a JSON5 document, Marshal and Format turn the
+// result into JSON.
+func ParseJSON5(input string) (*Node, error) {
+	return ParseWithOptions(input, JSON5)
+}
+
+// readToken5 reads the JSON5 tokens that don't start like a JSON token:
+// single quoted strings, numbers, Infinity, NaN and identifier keys.
+func (l *Lexer) readToken5() (Token, error) {
+	switch {
+	case l.ch == '\'':
+		return l.readString5()
+	case l.isNumber(l.ch) || l.ch == '-' || l.ch == '+' || l.ch == '.':
+		return l.readNumber5(), nil
+	}
+
+	r, _ := utf8.DecodeRuneInString(l.input[l.position:])
+	if !isIdentifierStart(r) {
+		return Token{}, &TokenError{CodeIllegalToken, "Not a legal token", string(r), l.pos()}
+	}
+
+	start := l.position
+	end := start
+	for end < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[end:])
+		if !isIdentifierPart(r) {
+			break
+		}
+		end += size
+	}
+	word := l.input[start:end]
+	pos := l.pos()
+	l.advanceTo(end)
+
+	if l.stringType() == NameString {
+		q := quote(word)
+		return Token{Type: NameString, Literal: q[1 : len(q)-1], State: l.findState(), Raw: word}, nil
+	}
+	switch word {
+	case string(True), string(False), string(Null):
+		return Token{Type: TokenType(word), Literal: word, State: l.findState()}, nil
+	case "Infinity", "NaN":
+		return Token{Type: Number, Literal: word, State: l.findState()}, nil
+	}
+	return Token{}, &TokenError{CodeIllegalToken, "Not a legal token", word, pos}
+}
+
+// readNumber5 reads a JSON5 number, the literal is the same number in JSON
+// notation unless it is Infinity or NaN.
+func (l *Lexer) readNumber5() Token {
+	start := l.position
+	end := start
+	if l.input[end] == '+' || l.input[end] == '-' {
+		end++
+	}
+
+	rest := l.input[end:]
+	switch {
+	case strings.HasPrefix(rest, "Infinity"):
+		end += len("Infinity")
+	case strings.HasPrefix(rest, "NaN"):
+		end += len("NaN")
+	case strings.HasPrefix(rest, "0x"), strings.HasPrefix(rest, "0X"):
+		end += 2
+		for end < len(l.input) && strings.IndexByte("0123456789abcdefABCDEF", l.input[end]) >= 0 {
+			end++
+		}
+	default:
+		for end < len(l.input) {
+			c := l.input[end]
+			if l.isNumber(c) || c == '.' || c == 'e' || c == 'E' ||
+				((c == '+' || c == '-') && (l.input[end-1] == 'e' || l.input[end-1] == 'E')) {
+				end++
+				continue
+			}
+			break
+		}
+	}
+
+	raw := l.input[start:end]
+	l.advanceTo(end)
+
+	t := Token{Type: Number, Literal: normalizeNumber5(raw), State: l.findState()}
+	if t.Literal != raw {
+		t.Raw = raw
+	}
+	return t
+}
+
+// normalizeNumber5 writes a JSON5 number in JSON notation, invalid numbers
+// are returned as they are.
+func normalizeNumber5(raw string) string {
+	lit, sign := raw, ""
+	if lit != "" && (lit[0] == '+' || lit[0] == '-') {
+		if lit[0] == '-' {
+			sign = "-"
+		}
+		lit = lit[1:]
+	}
+	if lit == "" || lit[0] == '+' || lit[0] == '-' {
+		return raw
+	}
+
+	switch {
+	case lit == "Infinity":
+		return sign + lit
+	case lit == "NaN":
+		return lit
+	case strings.HasPrefix(lit, "0x"), strings.HasPrefix(lit, "0X"):
+		n, ok := new(big.Int).SetString(lit[2:], 16)
+		if !ok {
+			return raw
+		}
+		return sign + n.String()
+	}
+
+	mantissa, exp := lit, ""
+	if i := strings.IndexAny(lit, "eE"); i >= 0 {
+		mantissa, exp = lit[:i], lit[i:]
+	}
+	if strings.HasPrefix(mantissa, ".") {
+		mantissa = "0" + mantissa
+	}
+	mantissa = strings.TrimSuffix(mantissa, ".")
+	return sign + mantissa + exp
+}
+
+// readString5 reads a JSON5 string quoted with ' or ", the literal is the
+// same string in JSON notation.
+func (l *Lexer) readString5() (Token, error) {
+	start := l.position
+	pos := l.pos()
+	q := l.ch
+
+	var b strings.Builder
+	i := start + 1
+	for {
+		if i >= len(l.input) {
+			return Token{}, &TokenError{CodeUnterminatedString, "Unterminated string", l.input[start:], pos}
+		}
+		r, size := utf8.DecodeRuneInString(l.input[i:])
+		if byte(r) == q && size == 1 {
+			i++
+			break
+		}
+
+		switch r {
+		case '\n', '\r':
+			return Token{}, &TokenError{CodeInvalidString, "Line break in string", l.input[start:i], pos}
+		case '\\':
+			s, n, err := unescape5(l.input[i+1:])
+			if err != nil {
+				err.Pos = pos
+				return Token{}, err
+			}
+			b.WriteString(s)
+			i += 1 + n
+		default:
+			b.WriteRune(r)
+			i += size
+		}
+	}
+
+	l.advanceTo(i)
+	lit := quote(b.String())
+	return Token{
+		Type:    l.stringType(),
+		Literal: lit[1 : len(lit)-1],
+		State:   l.findState(),
+		Raw:     l.input[start:i],
+	}, nil
+}
+
+// unescape5 decodes the JSON5 escape sequence at the start of s, which comes
+// right after the backslash. It returns the decoded text and the bytes read.
+func unescape5(s string) (string, int, *TokenError) {
+	if s == "" {
+		return "", 0, &TokenError{Code: CodeInvalidEscape, msg: "Unfinished escape sequence in", arg: `\`}
+	}
+
+	r, size := utf8.DecodeRuneInString(s)
+	switch r {
+	case 'b':
+		return "\b", 1, nil
+	case 'f':
+		return "\f", 1, nil
+	case 'n':
+		return "\n", 1, nil
+	case 'r':
+		return "\r", 1, nil
+	case 't':
+		return "\t", 1, nil
+	case 'v':
+		return "\v", 1, nil
+	case '0':
+		if len(s) > 1 && s[1] >= '0' && s[1] <= '9' {
+			return "", 0, &TokenError{Code: CodeInvalidEscape, msg: "Invalid escape character", arg: s[:2]}
+		}
+		return "\x00", 1, nil
+	case 'x':
+		if len(s) < 3 {
+			return "", 0, &TokenError{Code: CodeInvalidEscape, msg: "Invalid hex escape in", arg: s}
+		}
+		v, err := strconv.ParseUint(s[1:3], 16, 8)
+		if err != nil {
+			return "", 0, &TokenError{Code: CodeInvalidEscape, msg: "Invalid hex escape in", arg: s[:3]}
+		}
+		return string(rune(v)), 3, nil
+	case 'u':
+		r, ok := readHex(s[1:])
+		if !ok {
+			return "", 0, &TokenError{Code: CodeInvalidEscape, msg: "Invalid unicode escape in", arg: s}
+		}
+		if utf16.IsSurrogate(r) && strings.HasPrefix(s[5:], `\u`) {
+			if r2, ok := readHex(s[7:]); ok && utf16.DecodeRune(r, r2) != utf8.RuneError {
+				return string(utf16.DecodeRune(r, r2)), 11, nil
+			}
+		}
+		return string(r), 5, nil
+	case '\r':
+		// a line continuation
+		if strings.HasPrefix(s, "\r\n") {
+			return "", 2, nil
+		}
+		return "", 1, nil
+	case '\n', '\u2028', '\u2029':
+		return "", size, nil
+	case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return "", 0, &TokenError{Code: CodeInvalidEscape, msg: "Invalid escape character", arg: string(r)}
+	}
+	// every other character stands for itself
+	return string(r), size, nil
+}
+
+// advanceTo moves the lexer so the byte before end is the current one.
+func (l *Lexer) advanceTo(end int) {
+	for l.readPosition < end {
+		l.readChar()
+	}
+}
+
+// isNonFinite reports whether the literal is one of the JSON5 numbers that
+// JSON can't represent.
+func isNonFinite(lit string) bool {
+	return lit == "Infinity" || lit == "-Infinity" || lit == "NaN"
+}
+
+func isSpace5(r rune) bool {
+	return r == '\v' || r == '\f' || r == '\ufeff' || r == '\u2028' || r == '\u2029' || unicode.Is(unicode.Zs, r)
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '$' || r == '_' || unicode.IsLetter(r) || unicode.Is(unicode.Nl, r)
+}
+
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Pc) ||
+		r == '\u200c' || r == '\u200d'
+}
@@ -0,0 +1,171 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func TestLexer_JSON5(t *testing.T) {
+	tests := []struct {
+		name, input string
+		expected    []internal.Token
+	}{
+		{"Identifier keys", `{$key_1: 1, ünïcode: 2, null: 3}`, []internal.Token{
+			{Type: internal.OpeningCurly, Literal: "{"},
+			{Type: internal.NameString, Literal: "$key_1", Raw: "$key_1"},
+			{Type: internal.Colon, Literal: ":"},
+			{Type: internal.Number, Literal: "1"},
+			{Type: internal.Comma, Literal: ","},
+			{Type: internal.NameString, Literal: "ünïcode", Raw: "ünïcode"},
+			{Type: internal.Colon, Literal: ":"},
+			{Type: internal.Number, Literal: "2"},
+			{Type: internal.Comma, Literal: ","},
+			{Type: internal.NameString, Literal: "null", Raw: "null"},
+			{Type: internal.Colon, Literal: ":"},
+			{Type: internal.Number, Literal: "3"},
+			{Type: internal.ClosingCurly, Literal: "}"},
+		}},
+		{"Strings", "['it\\'s \"q\"', \"a\\\nb\", '\\x41\\v\\0\\a']", []internal.Token{
+			{Type: internal.OpeningBracket, Literal: "["},
+			{Type: internal.ValueString, Literal: `it's \"q\"`, Raw: `'it\'s "q"'`},
+			{Type: internal.Comma, Literal: ","},
+			{Type: internal.ValueString, Literal: "ab", Raw: "\"a\\\nb\""},
+			{Type: internal.Comma, Literal: ","},
+			{Type: internal.ValueString, Literal: `A\u000b\u0000a`, Raw: `'\x41\v\0\a'`},
+			{Type: internal.ClosingBracket, Literal: "]"},
+		}},
+		{"Numbers", "[0x1F, -0XA, +1, .5, 5., -.5e1, Infinity, -Infinity, +NaN, 1e+2]", []internal.Token{
+			{Type: internal.OpeningBracket, Literal: "["},
+			{Type: internal.Number, Literal: "31", Raw: "0x1F"},
+			{Type: internal.Comma, Literal: ","},
+			{Type: internal.Number, Literal: "-10", Raw: "-0XA"},
+			{Type: internal.Comma, Literal: ","},
+			{Type: internal.Number, Literal: "1", Raw: "+1"},
+			{Type: internal.Comma, Literal: ","},
+			{Type: internal.Number, Literal: "0.5", Raw: ".5"},
+			{Type: internal.Comma, Literal: ","},
+			{Type: internal.Number, Literal: "5", Raw: "5."},
+			{Type: internal.Comma, Literal: ","},
+			{Type: internal.Number, Literal: "-0.5e1", Raw: "-.5e1"},
+			{Type: internal.Comma, Literal: ","},
+			{Type: internal.Number, Literal: "Infinity"},
+			{Type: internal.Comma, Literal: ","},
+			{Type: internal.Number, Literal: "-Infinity"},
+			{Type: internal.Comma, Literal: ","},
+			{Type: internal.Number, Literal: "NaN", Raw: "+NaN"},
+			{Type: internal.Comma, Literal: ","},
+			{Type: internal.Number, Literal: "1e+2"},
+			{Type: internal.ClosingBracket, Literal: "]"},
+		}},
+		{"Whitespace and comments", "\ufeff\v\u00a0[ /* c */1 ]", []internal.Token{
+			{Type: internal.OpeningBracket, Literal: "["},
+			{Type: internal.Comment, Literal: "/* c */"},
+			{Type: internal.Number, Literal: "1"},
+			{Type: internal.ClosingBracket, Literal: "]"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := internal.NewLexerWithOptions(tt.input, internal.JSON5)
+			if err := l.ValidateTokens(); err != nil {
+				t.Fatal(err)
+			}
+			if len(l.Tokens) != len(tt.expected) {
+				t.Fatalf("expected %d tokens, got %v", len(tt.expected), l.Tokens)
+			}
+			for i, expected := range tt.expected {
+				actual := l.Tokens[i]
+				if actual.Type != expected.Type || actual.Literal != expected.Literal || actual.Raw != expected.Raw {
+					t.Errorf("expected %v, got %v", expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestLexer_JSON5Errors(t *testing.T) {
+	tests := []struct {
+		name, input, expected string
+	}{
+		{"Unknown identifier", `[undefined]`, "Not a legal token undefined at 1:2"},
+		{"Line break in string", "['a\nb']", "Line break in string 'a at 1:2"},
+		{"Unterminated string", `{a: 'b}`, "Unterminated string 'b} at 1:5"},
+		{"Octal escape", `['\01']`, `Invalid escape character 01 at 1:2`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := internal.NewLexerWithOptions(tt.input, internal.JSON5)
+			err := l.ValidateTokens()
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("expected %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestParseJSON5(t *testing.T) {
+	input := `// JSON5 example
+{
+  unquoted: 'and you can quote me on that',
+  singleQuotes: 'I can use "double quotes" here',
+  lineBreaks: "Look, Mom! \
+No \\n's!",
+  hexadecimal: 0xdecaf,
+  leadingDecimalPoint: .8675309, andTrailing: 8675309.,
+  positiveSign: +1,
+  trailingComma: 'in objects', andIn: ['arrays',],
+  "backwardsCompatible": "with JSON",
+  notNumbers: [Infinity, -Infinity, NaN],
+}`
+
+	doc, err := internal.ParseJSON5(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"unquoted":"and you can quote me on that","singleQuotes":"I can use \"double quotes\" here",` +
+		`"lineBreaks":"Look, Mom! No \\n's!","hexadecimal":912559,"leadingDecimalPoint":0.8675309,` +
+		`"andTrailing":8675309,"positiveSign":1,"trailingComma":"in objects","andIn":["arrays"],` +
+		`"backwardsCompatible":"with JSON","notNumbers":[null,null,null]}`
+	if actual := doc.String(); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+
+	l := internal.NewLexerWithOptions(input, internal.JSON5)
+	if err := l.ValidateTokens(); err != nil {
+		t.Fatal(err)
+	}
+	out, err := internal.Minify(l.Tokens, internal.MinifyOptions{ParseOptions: internal.JSON5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != expected {
+		t.Errorf("expected %s, got %s", expected, out)
+	}
+	if _, err := internal.Parse(string(out)); err != nil {
+		t.Errorf("expected strict JSON, got %v", err)
+	}
+}
+
+func TestParseJSON5_Invalid(t *testing.T) {
+	tests := []struct {
+		name, input string
+	}{
+		{"Leading zero", `[01]`},
+		{"Double sign", `[+-1]`},
+		{"Empty hex", `[0x]`},
+		{"Two trailing commas", `[1,,]`},
+		{"Identifier value", `{a: b}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := internal.ParseJSON5(tt.input); err == nil {
+				t.Errorf("expected an error for %s", tt.input)
+			}
+		})
+	}
+}
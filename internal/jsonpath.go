@@ -0,0 +1,1058 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// QueryError holds the error for when a JSONPath query can't be compiled
+type QueryError struct {
+	msg string
+	// Query is the full query
+	Query string
+	// Offset is the byte offset in the query where compiling failed
+	Offset int
+}
+
+func (q *QueryError) Error() string {
+	return fmt.Sprintf("%s at offset %d in query %q", q.msg, q.Offset, q.Query)
+}
+
+// PathNode is a node selected by a query.
+type PathNode struct {
+	// Path is the normalized path of the node, like $['users'][3]
+	Path string
+	Node *Node
+}
+
+// Query is a compiled JSONPath query as defined by RFC 9535.
+type Query struct {
+	raw      string
+	segments []segment
+}
+
+// CompileQuery parses a query like "$.items[?@.price < 10]".
+func CompileQuery(s string) (*Query, error) {
+	c := queryCompiler{input: s}
+	if !c.consume('$') {
+		return nil, c.errorf("Query should start with $")
+	}
+
+	segments, err := c.segments()
+	if err != nil {
+		return nil, err
+	}
+	if c.pos < len(c.input) {
+		return nil, c.errorf("Unexpected character %q", c.input[c.pos])
+	}
+	return &Query{raw: s, segments: segments}, nil
+}
+
+// String returns the query as it was compiled.
+func (q *Query) String() string {
+	return q.raw
+}
+
+// Select evaluates the query against the document and returns the selected
+// nodes in order.
+func (q *Query) Select(doc *Node) []PathNode {
+	return evalSegments(q.segments, PathNode{Path: "$", Node: doc}, doc)
+}
+
+type segment struct {
+	descendant bool
+	selectors  []selector
+}
+
+type selectorKind int
+
+const (
+	nameSelector selectorKind = iota
+	wildcardSelector
+	indexSelector
+	sliceSelector
+	filterSelector
+)
+
+type selector struct {
+	kind       selectorKind
+	name       string
+	index      int
+	start, end *int
+	step       int
+	filter     logicalExpr
+}
+
+// fnType is the type of a function parameter or result, see RFC 9535 section 2.4.1.
+type fnType int
+
+const (
+	valueType fnType = iota
+	logicalType
+	nodesType
+)
+
+// logicalExpr is an expression that evaluates to true or false inside of a filter.
+type logicalExpr interface {
+	eval(root, current *Node) bool
+}
+
+// valueExpr is an expression that evaluates to a single value inside of a
+// filter, nil is the special result Nothing.
+type valueExpr interface {
+	value(root, current *Node) *Node
+}
+
+type orExpr []logicalExpr
+
+func (o orExpr) eval(root, current *Node) bool {
+	for _, e := range o {
+		if e.eval(root, current) {
+			return true
+		}
+	}
+	return false
+}
+
+type andExpr []logicalExpr
+
+func (a andExpr) eval(root, current *Node) bool {
+	for _, e := range a {
+		if !e.eval(root, current) {
+			return false
+		}
+	}
+	return true
+}
+
+type notExpr struct {
+	expr logicalExpr
+}
+
+func (n notExpr) eval(root, current *Node) bool {
+	return !n.expr.eval(root, current)
+}
+
+type existsExpr struct {
+	query *filterQuery
+}
+
+func (e existsExpr) eval(root, current *Node) bool {
+	return len(e.query.nodes(root, current)) > 0
+}
+
+type compareExpr struct {
+	op          string
+	left, right valueExpr
+}
+
+func (c compareExpr) eval(root, current *Node) bool {
+	l, r := c.left.value(root, current), c.right.value(root, current)
+	switch c.op {
+	case "==":
+		return valuesEqual(l, r)
+	case "!=":
+		return !valuesEqual(l, r)
+	case "<":
+		return valueLess(l, r)
+	case "<=":
+		return valueLess(l, r) || valuesEqual(l, r)
+	case ">":
+		return valueLess(r, l)
+	case ">=":
+		return valueLess(r, l) || valuesEqual(l, r)
+	}
+	return false
+}
+
+func valuesEqual(a, b *Node) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(b)
+}
+
+func valueLess(a, b *Node) bool {
+	if a == nil || b == nil || a.Kind != b.Kind {
+		return false
+	}
+	switch a.Kind {
+	case NumberKind:
+		return compareNumbers(a.Token, b.Token) < 0
+	case StringKind:
+		return a.text < b.text
+	}
+	return false
+}
+
+type literalValue struct {
+	node *Node
+}
+
+func (l literalValue) value(_, _ *Node) *Node {
+	return l.node
+}
+
+type filterQuery struct {
+	relative bool
+	segments []segment
+}
+
+func (q *filterQuery) nodes(root, current *Node) []PathNode {
+	if q.relative {
+		return evalSegments(q.segments, PathNode{Path: "@", Node: current}, root)
+	}
+	return evalSegments(q.segments, PathNode{Path: "$", Node: root}, root)
+}
+
+func (q *filterQuery) value(root, current *Node) *Node {
+	if n := q.nodes(root, current); len(n) == 1 {
+		return n[0].Node
+	}
+	return nil
+}
+
+func (q *filterQuery) singular() bool {
+	for _, s := range q.segments {
+		if s.descendant || len(s.selectors) != 1 ||
+			(s.selectors[0].kind != nameSelector && s.selectors[0].kind != indexSelector) {
+			return false
+		}
+	}
+	return true
+}
+
+// fnValue holds a function argument or result, only the field matching its type is used.
+type fnValue struct {
+	value   *Node
+	nodes   []PathNode
+	logical bool
+}
+
+type fnDef struct {
+	params []fnType
+	result fnType
+	call   func(args []fnValue) fnValue
+}
+
+var queryFunctions = map[string]fnDef{
+	"length": {[]fnType{valueType}, valueType, func(args []fnValue) fnValue {
+		v := args[0].value
+		if v == nil {
+			return fnValue{}
+		}
+		switch v.Kind {
+		case StringKind:
+			return fnValue{value: newInt(utf8.RuneCountInString(v.text))}
+		case ArrayKind:
+			return fnValue{value: newInt(len(v.Elements))}
+		case ObjectKind:
+			return fnValue{value: newInt(len(v.Members))}
+		}
+		return fnValue{}
+	}},
+	"count": {[]fnType{nodesType}, valueType, func(args []fnValue) fnValue {
+		return fnValue{value: newInt(len(args[0].nodes))}
+	}},
+	"match": {[]fnType{valueType, valueType}, logicalType, func(args []fnValue) fnValue {
+		return fnValue{logical: regexpMatch(args[0].value, args[1].value, true)}
+	}},
+	"search": {[]fnType{valueType, valueType}, logicalType, func(args []fnValue) fnValue {
+		return fnValue{logical: regexpMatch(args[0].value, args[1].value, false)}
+	}},
+	"value": {[]fnType{nodesType}, valueType, func(args []fnValue) fnValue {
+		if len(args[0].nodes) == 1 {
+			return fnValue{value: args[0].nodes[0].Node}
+		}
+		return fnValue{}
+	}},
+}
+
+type funcArg struct {
+	value   valueExpr
+	nodes   *filterQuery
+	logical logicalExpr
+}
+
+type funcExpr struct {
+	def  fnDef
+	args []funcArg
+}
+
+func (f *funcExpr) call(root, current *Node) fnValue {
+	args := make([]fnValue, len(f.args))
+	for i, a := range f.args {
+		switch f.def.params[i] {
+		case valueType:
+			args[i].value = a.value.value(root, current)
+		case nodesType:
+			args[i].nodes = a.nodes.nodes(root, current)
+		case logicalType:
+			args[i].logical = a.logical.eval(root, current)
+		}
+	}
+	return f.def.call(args)
+}
+
+func (f *funcExpr) value(root, current *Node) *Node {
+	return f.call(root, current).value
+}
+
+func (f *funcExpr) eval(root, current *Node) bool {
+	r := f.call(root, current)
+	if f.def.result == nodesType {
+		return len(r.nodes) > 0
+	}
+	return r.logical
+}
+
+func newInt(i int) *Node {
+	return &Node{Kind: NumberKind, Token: Token{Type: Number, Literal: strconv.Itoa(i)}}
+}
+
+// regexpMatch implements match and search, the pattern is an I-Regexp (RFC 9485).
+func regexpMatch(s, pattern *Node, full bool) bool {
+	if s == nil || pattern == nil || s.Kind != StringKind || pattern.Kind != StringKind {
+		return false
+	}
+
+	re := iRegexpToGo(pattern.text)
+	if full {
+		re = `\A(?:` + re + `)\z`
+	}
+	r, err := regexp.Compile(re)
+	if err != nil {
+		return false
+	}
+	return r.MatchString(s.text)
+}
+
+// iRegexpToGo rewrites the dots outside of character classes, in I-Regexp they
+// match anything but line feeds and carriage returns.
+func iRegexpToGo(re string) string {
+	var b strings.Builder
+	inClass := false
+	for i := 0; i < len(re); i++ {
+		switch c := re[i]; {
+		case c == '\\' && i+1 < len(re):
+			b.WriteByte(c)
+			i++
+			b.WriteByte(re[i])
+		case c == '[':
+			inClass = true
+			b.WriteByte(c)
+		case c == ']':
+			inClass = false
+			b.WriteByte(c)
+		case c == '.' && !inClass:
+			b.WriteString(`[^\n\r]`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func evalSegments(segments []segment, start PathNode, root *Node) []PathNode {
+	nodes := []PathNode{start}
+	for _, seg := range segments {
+		var next []PathNode
+		for _, pn := range nodes {
+			if seg.descendant {
+				walkDescendants(pn, func(d PathNode) {
+					next = seg.apply(d, root, next)
+				})
+			} else {
+				next = seg.apply(pn, root, next)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// walkDescendants visits the node and then every descendant in document order.
+func walkDescendants(pn PathNode, visit func(PathNode)) {
+	visit(pn)
+	eachChild(pn, func(c PathNode) {
+		walkDescendants(c, visit)
+	})
+}
+
+func eachChild(pn PathNode, visit func(PathNode)) {
+	switch pn.Node.Kind {
+	case ObjectKind:
+		for _, m := range pn.Node.Members {
+			visit(PathNode{Path: pn.Path + normalizedName(m.Name), Node: m.Value})
+		}
+	case ArrayKind:
+		for i, e := range pn.Node.Elements {
+			visit(PathNode{Path: pn.Path + "[" + strconv.Itoa(i) + "]", Node: e})
+		}
+	}
+}
+
+func (seg segment) apply(pn PathNode, root *Node, out []PathNode) []PathNode {
+	for _, s := range seg.selectors {
+		out = s.apply(pn, root, out)
+	}
+	return out
+}
+
+func (s selector) apply(pn PathNode, root *Node, out []PathNode) []PathNode {
+	n := pn.Node
+	switch s.kind {
+	case nameSelector:
+		if n.Kind == ObjectKind {
+			if v := n.Lookup(s.name); v != nil {
+				out = append(out, PathNode{Path: pn.Path + normalizedName(s.name), Node: v})
+			}
+		}
+	case wildcardSelector:
+		eachChild(pn, func(c PathNode) {
+			out = append(out, c)
+		})
+	case indexSelector:
+		if n.Kind == ArrayKind {
+			i := s.index
+			if i < 0 {
+				i += len(n.Elements)
+			}
+			if i >= 0 && i < len(n.Elements) {
+				out = append(out, PathNode{Path: pn.Path + "[" + strconv.Itoa(i) + "]", Node: n.Elements[i]})
+			}
+		}
+	case sliceSelector:
+		if n.Kind == ArrayKind {
+			for _, i := range s.sliceIndexes(len(n.Elements)) {
+				out = append(out, PathNode{Path: pn.Path + "[" + strconv.Itoa(i) + "]", Node: n.Elements[i]})
+			}
+		}
+	case filterSelector:
+		eachChild(pn, func(c PathNode) {
+			if s.filter.eval(root, c.Node) {
+				out = append(out, c)
+			}
+		})
+	}
+	return out
+}
+
+// sliceIndexes follows the slice algorithm of RFC 9535 section 2.3.4.2.2.
+func (s selector) sliceIndexes(length int) []int {
+	if s.step == 0 {
+		return nil
+	}
+
+	normalize := func(i int) int {
+		if i >= 0 {
+			return i
+		}
+		return length + i
+	}
+
+	var idx []int
+	if s.step > 0 {
+		start, end := 0, length
+		if s.start != nil {
+			start = normalize(*s.start)
+		}
+		if s.end != nil {
+			end = normalize(*s.end)
+		}
+		lower, upper := min(max(start, 0), length), min(max(end, 0), length)
+		for i := lower; i < upper; i += s.step {
+			idx = append(idx, i)
+		}
+		return idx
+	}
+
+	start, end := length-1, -length-1
+	if s.start != nil {
+		start = normalize(*s.start)
+	}
+	if s.end != nil {
+		end = normalize(*s.end)
+	}
+	upper, lower := min(max(start, -1), length-1), min(max(end, -1), length-1)
+	for i := upper; lower < i; i += s.step {
+		idx = append(idx, i)
+	}
+	return idx
+}
+
+// normalizedName formats a member name as a normalized path segment.
+func normalizedName(name string) string {
+	var b strings.Builder
+	b.WriteString("['")
+	for _, r := range name {
+		switch r {
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '\\':
+			b.WriteString(`\\`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteString("']")
+	return b.String()
+}
+
+// maxQueryInt is the I-JSON integer range indexes and slice bounds must stay in.
+const maxQueryInt = 1<<53 - 1
+
+type queryCompiler struct {
+	input string
+	pos   int
+}
+
+func (c *queryCompiler) errorf(format string, args ...any) *QueryError {
+	return &QueryError{fmt.Sprintf(format, args...), c.input, c.pos}
+}
+
+func (c *queryCompiler) peek() byte {
+	if c.pos >= len(c.input) {
+		return 0
+	}
+	return c.input[c.pos]
+}
+
+func (c *queryCompiler) consume(ch byte) bool {
+	if c.peek() == ch {
+		c.pos++
+		return true
+	}
+	return false
+}
+
+func (c *queryCompiler) skipBlank() {
+	for c.pos < len(c.input) {
+		switch c.input[c.pos] {
+		case ' ', '\t', '\n', '\r':
+			c.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (c *queryCompiler) segments() ([]segment, error) {
+	var segments []segment
+	for {
+		start := c.pos
+		c.skipBlank()
+		if ch := c.peek(); ch != '.' && ch != '[' {
+			c.pos = start
+			return segments, nil
+		}
+
+		seg, err := c.segment()
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, seg)
+	}
+}
+
+func (c *queryCompiler) segment() (segment, error) {
+	if c.consume('[') {
+		selectors, err := c.bracketed()
+		return segment{selectors: selectors}, err
+	}
+
+	c.pos++
+	descendant := c.consume('.')
+	switch {
+	case c.consume('*'):
+		return segment{descendant, []selector{{kind: wildcardSelector}}}, nil
+	case descendant && c.consume('['):
+		selectors, err := c.bracketed()
+		return segment{descendant, selectors}, err
+	}
+
+	name := c.memberName()
+	if name == "" {
+		return segment{}, c.errorf("Expected a member name")
+	}
+	return segment{descendant, []selector{{kind: nameSelector, name: name}}}, nil
+}
+
+func (c *queryCompiler) memberName() string {
+	start := c.pos
+	for c.pos < len(c.input) {
+		ch := c.input[c.pos]
+		if ch == '_' || ch >= 0x80 || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') ||
+			(c.pos > start && ch >= '0' && ch <= '9') {
+			c.pos++
+			continue
+		}
+		break
+	}
+	return c.input[start:c.pos]
+}
+
+func (c *queryCompiler) bracketed() ([]selector, error) {
+	var selectors []selector
+	for {
+		c.skipBlank()
+		s, err := c.selector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, s)
+
+		c.skipBlank()
+		if c.consume(']') {
+			return selectors, nil
+		}
+		if !c.consume(',') {
+			return nil, c.errorf("Expected a comma or ]")
+		}
+	}
+}
+
+func (c *queryCompiler) selector() (selector, error) {
+	switch c.peek() {
+	case '\'', '"':
+		name, err := c.stringLiteral()
+		return selector{kind: nameSelector, name: name}, err
+	case '*':
+		c.pos++
+		return selector{kind: wildcardSelector}, nil
+	case '?':
+		c.pos++
+		c.skipBlank()
+		e, err := c.logicalOr()
+		return selector{kind: filterSelector, filter: e}, err
+	}
+
+	var start *int
+	if c.peek() != ':' {
+		i, err := c.integer()
+		if err != nil {
+			return selector{}, err
+		}
+		c.skipBlank()
+		if c.peek() != ':' {
+			return selector{kind: indexSelector, index: i}, nil
+		}
+		start = &i
+	}
+
+	s := selector{kind: sliceSelector, start: start, step: 1}
+	c.pos++
+	c.skipBlank()
+	if ch := c.peek(); ch == '-' || (ch >= '0' && ch <= '9') {
+		end, err := c.integer()
+		if err != nil {
+			return selector{}, err
+		}
+		s.end = &end
+		c.skipBlank()
+	}
+	if c.consume(':') {
+		c.skipBlank()
+		if ch := c.peek(); ch == '-' || (ch >= '0' && ch <= '9') {
+			step, err := c.integer()
+			if err != nil {
+				return selector{}, err
+			}
+			s.step = step
+		}
+	}
+	return s, nil
+}
+
+func (c *queryCompiler) integer() (int, error) {
+	start := c.pos
+	c.consume('-')
+	digits := c.pos
+	for c.pos < len(c.input) && c.input[c.pos] >= '0' && c.input[c.pos] <= '9' {
+		c.pos++
+	}
+
+	lit := c.input[start:c.pos]
+	if c.pos == digits || (c.input[digits] == '0' && (c.pos-digits > 1 || digits > start)) {
+		c.pos = start
+		return 0, c.errorf("Invalid integer %q", lit)
+	}
+
+	i, err := strconv.Atoi(lit)
+	if err != nil || i > maxQueryInt || i < -maxQueryInt {
+		c.pos = start
+		return 0, c.errorf("Integer %s is out of range", lit)
+	}
+	return i, nil
+}
+
+// stringLiteral reads a single or double quoted string with its escapes.
+func (c *queryCompiler) stringLiteral() (string, error) {
+	quoteCh := c.input[c.pos]
+	c.pos++
+
+	var b strings.Builder
+	for {
+		if c.pos >= len(c.input) {
+			return "", c.errorf("Unterminated string")
+		}
+
+		ch := c.input[c.pos]
+		switch {
+		case ch == quoteCh:
+			c.pos++
+			return b.String(), nil
+		case ch < 0x20:
+			return "", c.errorf("Control character in string")
+		case ch != '\\':
+			b.WriteByte(ch)
+			c.pos++
+			continue
+		}
+
+		c.pos++
+		esc := c.peek()
+		c.pos++
+		switch esc {
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '/', '\\', quoteCh:
+			b.WriteByte(esc)
+		case 'u':
+			r, err := c.unicodeEscape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+		default:
+			c.pos--
+			return "", c.errorf("Invalid escape character %q", esc)
+		}
+	}
+}
+
+func (c *queryCompiler) unicodeEscape() (rune, error) {
+	r, ok := readHex(c.input[c.pos:])
+	if !ok {
+		return 0, c.errorf("Invalid unicode escape")
+	}
+	c.pos += 4
+
+	switch {
+	case r >= 0xDC00 && r <= 0xDFFF:
+		return 0, c.errorf("Lone low surrogate")
+	case r >= 0xD800 && r <= 0xDBFF:
+		if !strings.HasPrefix(c.input[c.pos:], `\u`) {
+			return 0, c.errorf("Lone high surrogate")
+		}
+		low, ok := readHex(c.input[c.pos+2:])
+		if !ok || low < 0xDC00 || low > 0xDFFF {
+			return 0, c.errorf("Invalid low surrogate")
+		}
+		c.pos += 6
+		return utf16.DecodeRune(r, low), nil
+	}
+	return r, nil
+}
+
+func (c *queryCompiler) logicalOr() (logicalExpr, error) {
+	var or orExpr
+	for {
+		e, err := c.logicalAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, e)
+
+		start := c.pos
+		c.skipBlank()
+		if !strings.HasPrefix(c.input[c.pos:], "||") {
+			c.pos = start
+			break
+		}
+		c.pos += 2
+		c.skipBlank()
+	}
+
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (c *queryCompiler) logicalAnd() (logicalExpr, error) {
+	var and andExpr
+	for {
+		e, err := c.basic()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, e)
+
+		start := c.pos
+		c.skipBlank()
+		if !strings.HasPrefix(c.input[c.pos:], "&&") {
+			c.pos = start
+			break
+		}
+		c.pos += 2
+		c.skipBlank()
+	}
+
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (c *queryCompiler) basic() (logicalExpr, error) {
+	if c.consume('!') {
+		c.skipBlank()
+		if c.peek() == '(' {
+			e, err := c.paren()
+			return notExpr{e}, err
+		}
+
+		start := c.pos
+		p, err := c.primary()
+		if err != nil {
+			return nil, err
+		}
+		e, err := c.testExpr(p, start)
+		return notExpr{e}, err
+	}
+
+	if c.peek() == '(' {
+		return c.paren()
+	}
+
+	start := c.pos
+	left, err := c.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	afterLeft := c.pos
+	c.skipBlank()
+	op := c.comparisonOp()
+	if op == "" {
+		c.pos = afterLeft
+		return c.testExpr(left, start)
+	}
+
+	l, err := c.comparable(left, start)
+	if err != nil {
+		return nil, err
+	}
+
+	c.skipBlank()
+	rightStart := c.pos
+	right, err := c.primary()
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.comparable(right, rightStart)
+	if err != nil {
+		return nil, err
+	}
+	return compareExpr{op, l, r}, nil
+}
+
+func (c *queryCompiler) paren() (logicalExpr, error) {
+	c.pos++
+	c.skipBlank()
+	e, err := c.logicalOr()
+	if err != nil {
+		return nil, err
+	}
+	c.skipBlank()
+	if !c.consume(')') {
+		return nil, c.errorf("Expected )")
+	}
+	return e, nil
+}
+
+func (c *queryCompiler) comparisonOp() string {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if strings.HasPrefix(c.input[c.pos:], op) {
+			c.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+// comparable checks that the primary can be used on either side of a comparison.
+func (c *queryCompiler) comparable(p any, start int) (valueExpr, error) {
+	switch p := p.(type) {
+	case literalValue:
+		return p, nil
+	case *filterQuery:
+		if p.singular() {
+			return p, nil
+		}
+		return nil, &QueryError{"Query in a comparison must be singular", c.input, start}
+	case *funcExpr:
+		if p.def.result == valueType {
+			return p, nil
+		}
+	}
+	return nil, &QueryError{"Function result can't be compared", c.input, start}
+}
+
+// testExpr checks that the primary can be used as a test on its own.
+func (c *queryCompiler) testExpr(p any, start int) (logicalExpr, error) {
+	switch p := p.(type) {
+	case *filterQuery:
+		return existsExpr{p}, nil
+	case *funcExpr:
+		if p.def.result != valueType {
+			return p, nil
+		}
+		return nil, &QueryError{"Function result must be compared", c.input, start}
+	}
+	return nil, &QueryError{"Literal must be compared", c.input, start}
+}
+
+// primary reads a literal, a filter query or a function call.
+func (c *queryCompiler) primary() (any, error) {
+	switch ch := c.peek(); {
+	case ch == '@' || ch == '$':
+		c.pos++
+		segments, err := c.segments()
+		if err != nil {
+			return nil, err
+		}
+		return &filterQuery{relative: ch == '@', segments: segments}, nil
+	case ch == '\'' || ch == '"':
+		s, err := c.stringLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return literalValue{NewString(s)}, nil
+	case ch == '-' || (ch >= '0' && ch <= '9'):
+		return c.number()
+	case ch >= 'a' && ch <= 'z':
+		start := c.pos
+		for c.pos < len(c.input) {
+			ch := c.input[c.pos]
+			if (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9') || ch == '_' {
+				c.pos++
+				continue
+			}
+			break
+		}
+
+		name := c.input[start:c.pos]
+		if c.peek() == '(' {
+			return c.function(name, start)
+		}
+		switch name {
+		case "true":
+			return literalValue{NewBool(true)}, nil
+		case "false":
+			return literalValue{NewBool(false)}, nil
+		case "null":
+			return literalValue{NewNull()}, nil
+		}
+		c.pos = start
+		return nil, c.errorf("Unknown literal %q", name)
+	}
+	return nil, c.errorf("Expected a literal, query or function")
+}
+
+func (c *queryCompiler) number() (any, error) {
+	start := c.pos
+	for c.pos < len(c.input) && strings.IndexByte("0123456789-+.eE", c.input[c.pos]) >= 0 {
+		c.pos++
+	}
+
+	n, err := NewNumber(c.input[start:c.pos])
+	if err != nil {
+		c.pos = start
+		return nil, c.errorf("Invalid number")
+	}
+	return literalValue{n}, nil
+}
+
+func (c *queryCompiler) function(name string, start int) (*funcExpr, error) {
+	def, ok := queryFunctions[name]
+	if !ok {
+		c.pos = start
+		return nil, c.errorf("Unknown function %q", name)
+	}
+
+	c.pos++
+	f := &funcExpr{def: def}
+	c.skipBlank()
+	for !c.consume(')') {
+		if len(f.args) > 0 && !c.consume(',') {
+			return nil, c.errorf("Expected a comma or )")
+		}
+		if len(f.args) == len(def.params) {
+			return nil, c.errorf("Too many arguments for %s", name)
+		}
+		c.skipBlank()
+
+		arg, err := c.argument(def.params[len(f.args)])
+		if err != nil {
+			return nil, err
+		}
+		f.args = append(f.args, arg)
+		c.skipBlank()
+	}
+
+	if len(f.args) != len(def.params) {
+		return nil, &QueryError{fmt.Sprintf("Not enough arguments for %s", name), c.input, start}
+	}
+	return f, nil
+}
+
+func (c *queryCompiler) argument(typ fnType) (funcArg, error) {
+	if typ == logicalType {
+		e, err := c.logicalOr()
+		return funcArg{logical: e}, err
+	}
+
+	start := c.pos
+	p, err := c.primary()
+	if err != nil {
+		return funcArg{}, err
+	}
+
+	if typ == nodesType {
+		if q, ok := p.(*filterQuery); ok {
+			return funcArg{nodes: q}, nil
+		}
+		return funcArg{}, &QueryError{"Argument must be a query", c.input, start}
+	}
+
+	v, err := c.comparable(p, start)
+	return funcArg{value: v}, err
+}
@@ -0,0 +1,207 @@
+package internal_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+const storeDoc = `{ "store": {
+    "book": [
+      { "category": "reference",
+        "author": "Nigel Rees",
+        "title": "Sayings of the Century",
+        "price": 8.95
+      },
+      { "category": "fiction",
+        "author": "Evelyn Waugh",
+        "title": "Sword of Honour",
+        "price": 12.99
+      },
+      { "category": "fiction",
+        "author": "Herman Melville",
+        "title": "Moby Dick",
+        "isbn": "0-553-21311-3",
+        "price": 8.99
+      },
+      { "category": "fiction",
+        "author": "J. R. R. Tolkien",
+        "title": "The Lord of the Rings",
+        "isbn": "0-395-19395-8",
+        "price": 22.99
+      }
+    ],
+    "bicycle": {
+      "color": "red",
+      "price": 399
+    }
+  }
+}`
+
+func TestQuery_Select(t *testing.T) {
+	tests := []struct {
+		name, query string
+		expected    []string
+	}{
+		{"Root", `$`, []string{`$`}},
+		{"Authors", `$.store.book[*].author`, []string{
+			`$['store']['book'][0]['author']`,
+			`$['store']['book'][1]['author']`,
+			`$['store']['book'][2]['author']`,
+			`$['store']['book'][3]['author']`,
+		}},
+		{"Recursive authors", `$..author`, []string{
+			`$['store']['book'][0]['author']`,
+			`$['store']['book'][1]['author']`,
+			`$['store']['book'][2]['author']`,
+			`$['store']['book'][3]['author']`,
+		}},
+		{"Store prices", `$.store..price`, []string{
+			`$['store']['book'][0]['price']`,
+			`$['store']['book'][1]['price']`,
+			`$['store']['book'][2]['price']`,
+			`$['store']['book'][3]['price']`,
+			`$['store']['bicycle']['price']`,
+		}},
+		{"Third book", `$..book[2]`, []string{`$['store']['book'][2]`}},
+		{"Last book", `$..book[-1]`, []string{`$['store']['book'][3]`}},
+		{"First two books", `$..book[0,1]`, []string{`$['store']['book'][0]`, `$['store']['book'][1]`}},
+		{"Slice", `$..book[:2]`, []string{`$['store']['book'][0]`, `$['store']['book'][1]`}},
+		{"Reverse slice", `$.store.book[::-2]`, []string{`$['store']['book'][3]`, `$['store']['book'][1]`}},
+		{"Bracket names", `$['store']["bicycle"]`, []string{`$['store']['bicycle']`}},
+		{"Books with isbn", `$..book[?@.isbn]`, []string{`$['store']['book'][2]`, `$['store']['book'][3]`}},
+		{"Cheap books", `$..book[?@.price<10]`, []string{`$['store']['book'][0]`, `$['store']['book'][2]`}},
+		{"Logical filter", `$.store.book[?@.price > 10 && !(@.category == 'fiction' && @.isbn)]`, []string{
+			`$['store']['book'][1]`,
+		}},
+		{"Absolute query in filter", `$.store.book[?@.price > $.store.book[0].price].title`, []string{
+			`$['store']['book'][1]['title']`,
+			`$['store']['book'][2]['title']`,
+			`$['store']['book'][3]['title']`,
+		}},
+		{"Length function", `$.store.book[?length(@.title) < 10].title`, []string{
+			`$['store']['book'][2]['title']`,
+		}},
+		{"Count function", `$.store[?count(@.*) == 2]`, []string{`$['store']['bicycle']`}},
+		{"Match function", `$.store.book[?match(@.author, 'J.*')].author`, []string{
+			`$['store']['book'][3]['author']`,
+		}},
+		{"Search function", `$.store.book[?search(@.title, "of")].title`, []string{
+			`$['store']['book'][0]['title']`,
+			`$['store']['book'][1]['title']`,
+			`$['store']['book'][3]['title']`,
+		}},
+		{"Value function", `$.store[?value(@..color) == "red"]`, []string{`$['store']['bicycle']`}},
+		{"Missing member", `$.store.car`, nil},
+		{"Zero step", `$.store.book[::0]`, nil},
+	}
+
+	doc, err := internal.Parse(storeDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := internal.CompileQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var actual []string
+			for _, r := range q.Select(doc) {
+				actual = append(actual, r.Path)
+			}
+			if !slices.Equal(actual, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestQuery_Values(t *testing.T) {
+	tests := []struct {
+		name, doc, query string
+		expected         []string
+	}{
+		{"Equal Nothing", `[{"a": 1}, {"b": 2}]`, `$[?@.c == @.d]`, []string{`{"a":1}`, `{"b":2}`}},
+		{"Number equality", `[1, 1.0, 10e-1, 2]`, `$[?@ == 1]`, []string{`1`, `1.0`, `10e-1`}},
+		{"Deep equality", `[[1, {"a": true}], [1]]`, `$[?@ == $[0]]`, []string{`[1,{"a":true}]`}},
+		{"String order", `["a", "b", "c", 1]`, `$[?@ >= "b"]`, []string{`"b"`, `"c"`}},
+		{"Escaped name", `{"a'b": 1, "c\n": 2}`, `$["a'b"]`, []string{`1`}},
+		{"Descendant wildcard", `{"a": [1, {"b": 2}]}`, `$..*`, []string{`[1,{"b":2}]`, `1`, `{"b":2}`, `2`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := internal.Parse(tt.doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			q, err := internal.CompileQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var actual []string
+			for _, r := range q.Select(doc) {
+				actual = append(actual, r.Node.String())
+			}
+			if !slices.Equal(actual, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestQuery_NormalizedPaths(t *testing.T) {
+	doc, err := internal.Parse(`{"a'b": {"c\\d": {"\u000b": 1}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, _ := internal.CompileQuery(`$..*`)
+
+	var actual []string
+	for _, r := range q.Select(doc) {
+		actual = append(actual, r.Path)
+	}
+	expected := []string{`$['a\'b']`, `$['a\'b']['c\\d']`, `$['a\'b']['c\\d']['\u000b']`}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestCompileQuery_Invalid(t *testing.T) {
+	tests := []string{
+		``,
+		`store`,
+		`$.`,
+		`$..`,
+		`$.1a`,
+		`$[`,
+		`$[01]`,
+		`$[-0]`,
+		`$[9007199254740992]`,
+		`$['unterminated]`,
+		`$['\a']`,
+		`$["\uD800"]`,
+		`$[?@.a == 1 ||]`,
+		`$[?@..a == 1]`,
+		`$[?@.* == 1]`,
+		`$[?1]`,
+		`$[?length(@.a)]`,
+		`$[?count(1) == 1]`,
+		`$[?match(@.a) == 1]`,
+		`$[?foo(@.a)]`,
+		`$.a `,
+	}
+
+	for _, query := range tests {
+		t.Run(query, func(t *testing.T) {
+			if q, err := internal.CompileQuery(query); err == nil {
+				t.Errorf("expected an error, got %v", q)
+			}
+		})
+	}
+}
@@ -2,15 +2,24 @@ package internal
 
 import (
 	"fmt"
+	"unicode/utf8"
 )
 
 // TokenError holds the error for when a token is illegal
 type TokenError struct {
+	// Code names the kind of error, like CodeIllegalToken
+	Code     string
 	msg, arg string
+	// Pos is where the lexer found the error, it is zero for errors found
+	// outside of the lexer
+	Pos Position
 }
 
 func (t *TokenError) Error() string {
-	return fmt.Sprintf("%s %s", t.msg, t.arg)
+	if t.Pos.Line == 0 {
+		return fmt.Sprintf("%s %s", t.msg, t.arg)
+	}
+	return fmt.Sprintf("%s %s at %v", t.msg, t.arg, t.Pos)
 }
 
 // TokenType is a string.
@@ -19,11 +28,29 @@ type TokenType string
 // TokenState is a string.
 type TokenState string
 
+// Position is where a token starts in the input.
+type Position struct {
+	// Offset is the byte offset, starting at 0
+	Offset int
+	// Line starts at 1
+	Line int
+	// Column is the byte column in the line, starting at 1
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
 // Token holds what a token should represent.
 type Token struct {
 	Type    TokenType
 	Literal string
 	State   TokenState
+	Pos     Position
+	// Raw is the token as it was written when that differs from the literal,
+	// like JSON5 strings and numbers that are normalized to JSON
+	Raw string
 }
 
 // Lexer is what we use to make sure that all Tokens are valid.
@@ -32,8 +59,16 @@ type Lexer struct {
 	position     int
 	readPosition int
 	ch           byte
-	state        *Stack[TokenState]
-	Tokens       []Token
+	line         int
+	lineStart    int
+	// base is the offset of the input in a bigger one, like a line of NDJSON
+	base   int
+	state  *Stack[TokenState]
+	Tokens []Token
+	// Options says which dialect to lex, whether comments are Comment tokens
+	// instead of errors and how deep values may nest. JSON5 strings,
+	// identifier keys and numbers get a JSON literal and keep their text in Raw
+	Options ParseOptions
 }
 
 const (
@@ -83,20 +118,41 @@ const (
 	True TokenType = "true"
 	// False marks a primitive false boolean
 	False TokenType = "false"
+
+	// Comment marks a // or /* */ comment, the literal holds all of it
+	Comment TokenType = "comment"
 )
 
 // NewLexer creates a pointer to a Lexer.
 func NewLexer(input string) *Lexer {
-	l := Lexer{input: input, state: NewStack[TokenState]()}
+	l := Lexer{input: input, line: 1, state: NewStack[TokenState]()}
 	l.readChar()
 	return &l
 }
 
+// NewLexerWithOptions creates a pointer to a Lexer that uses the options.
+func NewLexerWithOptions(input string, opts ParseOptions) *Lexer {
+	l := NewLexer(input)
+	l.Options = opts
+	return l
+}
+
+// newLexerAt creates a Lexer for input that starts at the position in a
+// bigger input, so positions point into the bigger one.
+func newLexerAt(input string, opts ParseOptions, start Position) *Lexer {
+	l := NewLexerWithOptions(input, opts)
+	l.line = start.Line
+	l.lineStart = 1 - start.Column
+	l.base = start.Offset
+	return l
+}
+
 // ValidateTokens returns the next token.
 func (l *Lexer) ValidateTokens() error {
 	idx := 0
 	for ; idx < len(l.input); idx++ {
 		l.skipWhiteSpace()
+		pos := l.pos()
 
 		switch l.ch {
 		case '{':
@@ -106,62 +162,101 @@ func (l *Lexer) ValidateTokens() error {
 					Literal: string(l.ch),
 					Type:    OpeningCurly,
 					State:   StartObject,
+					Pos:     pos,
 				},
 			)
-			l.state.Push(InsideObject)
+			if err := l.push(InsideObject, pos); err != nil {
+				return err
+			}
 		case '}':
-			if s := l.state.Pop(); s != InsideObject {
-				return &TokenError{"Should be inside an object. Instead got", string(s)}
+			if s := l.findState(); s != InsideObject {
+				return &TokenError{CodeUnbalancedBrackets, "Should be inside an object. Instead got", string(s), pos}
 			}
+			l.state.Pop()
 			l.Tokens = append(
 				l.Tokens,
-				Token{Literal: string(l.ch), Type: ClosingCurly, State: EndObject},
+				Token{Literal: string(l.ch), Type: ClosingCurly, State: EndObject, Pos: pos},
 			)
 		case '[':
 			l.Tokens = append(
 				l.Tokens,
-				Token{Literal: string(l.ch), Type: OpeningBracket, State: StartArray},
+				Token{Literal: string(l.ch), Type: OpeningBracket, State: StartArray, Pos: pos},
 			)
-			l.state.Push(InsideArray)
+			if err := l.push(InsideArray, pos); err != nil {
+				return err
+			}
 		case ']':
-			if s := l.state.Pop(); s != InsideArray {
-				return &TokenError{"Should be inside an object. Instead got", string(s)}
+			if s := l.findState(); s != InsideArray {
+				return &TokenError{CodeUnbalancedBrackets, "Should be inside an array. Instead got", string(s), pos}
 			}
+			l.state.Pop()
 			l.Tokens = append(
 				l.Tokens,
-				Token{Literal: string(l.ch), Type: ClosingBracket, State: EndArray},
+				Token{Literal: string(l.ch), Type: ClosingBracket, State: EndArray, Pos: pos},
 			)
 		case ':':
 			l.Tokens = append(
 				l.Tokens,
-				Token{Literal: string(l.ch), Type: Colon, State: l.findState()},
+				Token{Literal: string(l.ch), Type: Colon, State: l.findState(), Pos: pos},
 			)
 		case ',':
 			l.Tokens = append(
 				l.Tokens,
-				Token{Literal: string(l.ch), Type: Comma, State: l.findState()},
+				Token{Literal: string(l.ch), Type: Comma, State: l.findState(), Pos: pos},
 			)
+		case '/':
+			if !l.Options.comments() {
+				return &TokenError{CodeIllegalToken, "Not a legal token", string(l.ch), pos}
+			}
+			t, err := l.readComment()
+			if err != nil {
+				return err
+			}
+			t.Pos = pos
+			l.Tokens = append(l.Tokens, t)
 		case '"':
-			l.Tokens = append(l.Tokens, l.readString())
+			var t Token
+			if l.Options.json5() {
+				var err error
+				if t, err = l.readString5(); err != nil {
+					return err
+				}
+			} else {
+				t = l.readString()
+			}
+			t.Pos = pos
+			l.Tokens = append(l.Tokens, t)
 		case 0:
 			if len(l.state.state) != 0 {
-				return fmt.Errorf(
-					"Length of the state should be 0. Instead got %d",
-					len(l.state.state),
-				)
+				return &TokenError{
+					CodeUnclosedBrackets,
+					"Length of the state should be 0. Instead got",
+					fmt.Sprint(len(l.state.state)),
+					pos,
+				}
 			}
 			return nil
 		default:
-			if l.isNumber(l.ch) || l.ch == '-' {
-				l.Tokens = append(l.Tokens, l.readNumber())
+			if l.Options.json5() {
+				t, err := l.readToken5()
+				if err != nil {
+					return err
+				}
+				t.Pos = pos
+				l.Tokens = append(l.Tokens, t)
+			} else if l.isNumber(l.ch) || l.ch == '-' {
+				t := l.readNumber()
+				t.Pos = pos
+				l.Tokens = append(l.Tokens, t)
 			} else if l.isLiteral(l.ch) {
 				literal, err := l.readLiteral()
 				if err != nil {
 					return err
 				}
+				literal.Pos = pos
 				l.Tokens = append(l.Tokens, *literal)
 			} else {
-				return &TokenError{"Not a legal token", string(l.ch)}
+				return &TokenError{CodeIllegalToken, "Not a legal token", string(l.ch), pos}
 			}
 		}
 
@@ -177,7 +272,7 @@ func (l *Lexer) readLiteral() (*Token, error) {
 	case 't':
 		for _, c := range True[1:] {
 			if c != rune(l.peek()) {
-				return nil, &TokenError{arg: string(l.peek()), msg: "Character was not true"}
+				return nil, &TokenError{Code: CodeIllegalToken, arg: string(l.peek()), msg: "Character was not true", Pos: l.pos()}
 			}
 			l.readChar()
 		}
@@ -185,7 +280,7 @@ func (l *Lexer) readLiteral() (*Token, error) {
 	case 'f':
 		for _, c := range False[1:] {
 			if c != rune(l.peek()) {
-				return nil, &TokenError{arg: string(l.peek()), msg: "Character was not false"}
+				return nil, &TokenError{Code: CodeIllegalToken, arg: string(l.peek()), msg: "Character was not false", Pos: l.pos()}
 			}
 			l.readChar()
 		}
@@ -193,7 +288,7 @@ func (l *Lexer) readLiteral() (*Token, error) {
 	case 'n':
 		for _, c := range Null[1:] {
 			if c != rune(l.peek()) {
-				return nil, &TokenError{arg: string(l.peek()), msg: "Character was not null"}
+				return nil, &TokenError{Code: CodeIllegalToken, arg: string(l.peek()), msg: "Character was not null", Pos: l.pos()}
 			}
 			l.readChar()
 		}
@@ -248,30 +343,77 @@ func (l *Lexer) readString() Token {
 				return t
 			}
 
-			if len(l.Tokens) == 0 {
-				t.Type = ValueString
-				return t
-			}
+			t.Type = l.stringType()
+			return t
+		} else if l.ch == 0 {
+			return Token{Type: Illegal, Literal: l.input[position:], State: Invalid}
+		}
+	}
+}
 
-			pt := l.Tokens[len(l.Tokens)-1]
+// stringType returns whether a string starting here is a member name or a
+// value, it is empty when the string can't start here.
+func (l *Lexer) stringType() TokenType {
+	pt, ok := l.lastToken()
+	if !ok {
+		return ValueString
+	}
 
-			if pt.Type == OpeningCurly || (pt.Type == Comma && l.state.Peek() == InsideObject) {
-				t.Type = NameString
-			} else if l.state.Peek() == InsideArray && (pt.Type == Comma || pt.Type == OpeningBracket) {
-				t.Type = ValueString
-			} else if pt.Type == Colon {
-				t.Type = ValueString
+	if pt.Type == OpeningCurly || (pt.Type == Comma && l.findState() == InsideObject) {
+		return NameString
+	} else if l.findState() == InsideArray && (pt.Type == Comma || pt.Type == OpeningBracket) {
+		return ValueString
+	} else if pt.Type == Colon {
+		return ValueString
+	}
+	return ""
+}
+
+// readComment reads a comment starting at the current slash, it stops on the
+// last character of the comment.
+func (l *Lexer) readComment() (Token, error) {
+	position := l.position
+	start := l.pos()
+
+	switch l.peek() {
+	case '/':
+		for l.peek() != '\n' && l.peek() != 0 {
+			l.readChar()
+		}
+	case '*':
+		l.readChar()
+		for {
+			l.readChar()
+			if l.ch == 0 {
+				return Token{}, &TokenError{CodeUnterminatedComment, "Unterminated block comment", "/*", start}
 			}
-			return t
-		} else if l.ch == 0 {
-			if l.input[l.position-1] != '"' {
-				return Token{Type: Illegal, Literal: l.input[position:l.position], State: Invalid}
+			if l.ch == '*' && l.peek() == '/' {
+				l.readChar()
+				break
 			}
 		}
+	default:
+		return Token{}, &TokenError{CodeIllegalToken, "Not a legal token", "/", start}
 	}
+
+	return Token{Type: Comment, Literal: l.input[position:l.readPosition], State: l.findState()}, nil
+}
+
+// lastToken returns the last token that isn't a comment.
+func (l *Lexer) lastToken() (Token, bool) {
+	for i := len(l.Tokens) - 1; i >= 0; i-- {
+		if l.Tokens[i].Type != Comment {
+			return l.Tokens[i], true
+		}
+	}
+	return Token{}, false
 }
 
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.lineStart = l.readPosition
+	}
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
@@ -306,6 +448,14 @@ func (l *Lexer) skipWhiteSpace() {
 			continue
 		}
 
+		if l.Options.json5() && l.ch != 0 {
+			if r, size := utf8.DecodeRuneInString(l.input[l.position:]); isSpace5(r) {
+				l.advanceTo(l.position + size)
+				l.readChar()
+				continue
+			}
+		}
+
 		if l.ch == '\\' && (l.peek() == 't' || l.peek() == 'n' || l.peek() == 'r') {
 			l.readChar()
 			l.readChar()
@@ -315,6 +465,19 @@ func (l *Lexer) skipWhiteSpace() {
 	}
 }
 
+// push enters an object or array, failing when that goes past MaxDepth.
+func (l *Lexer) push(s TokenState, pos Position) error {
+	if l.Options.MaxDepth > 0 && len(l.state.state) >= l.Options.MaxDepth {
+		return &TokenError{CodeTooDeep, "Nesting is deeper than the limit of", fmt.Sprint(l.Options.MaxDepth), pos}
+	}
+	l.state.Push(s)
+	return nil
+}
+
+func (l *Lexer) pos() Position {
+	return Position{Offset: l.base + l.position, Line: l.line, Column: l.position - l.lineStart + 1}
+}
+
 func (l *Lexer) findState() TokenState {
 	if l.state.IsEmpty() {
 		return Invalid
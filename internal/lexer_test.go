@@ -108,3 +108,59 @@ func TestLexer(t *testing.T) {
 		})
 	}
 }
+
+func TestLexer_Comments(t *testing.T) {
+	input := "// header\n{ /* a */ \"key\": 1, // trailing\n\"b\": [/**/]}"
+	expected := []internal.Token{
+		{Literal: "// header", Type: internal.Comment},
+		{Literal: "{", Type: internal.OpeningCurly},
+		{Literal: "/* a */", Type: internal.Comment},
+		{Literal: "key", Type: internal.NameString},
+		{Literal: ":", Type: internal.Colon},
+		{Literal: "1", Type: internal.Number},
+		{Literal: ",", Type: internal.Comma},
+		{Literal: "// trailing", Type: internal.Comment},
+		{Literal: "b", Type: internal.NameString},
+		{Literal: ":", Type: internal.Colon},
+		{Literal: "[", Type: internal.OpeningBracket},
+		{Literal: "/**/", Type: internal.Comment},
+		{Literal: "]", Type: internal.ClosingBracket},
+		{Literal: "}", Type: internal.ClosingCurly},
+	}
+
+	l := internal.NewLexerWithOptions(input, internal.JSONC)
+	if err := l.ValidateTokens(); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Tokens) != len(expected) {
+		t.Fatalf("expected %d tokens, got %v", len(expected), l.Tokens)
+	}
+	for i, expected := range expected {
+		actual := l.Tokens[i]
+		if actual.Type != expected.Type || actual.Literal != expected.Literal {
+			t.Errorf("expected %v, got %v", expected, actual)
+		}
+	}
+}
+
+func TestLexer_CommentErrors(t *testing.T) {
+	tests := []struct {
+		name, input   string
+		allowComments bool
+		expected      string
+	}{
+		{"Comments not allowed", "[1] // c", false, "Not a legal token / at 1:5"},
+		{"Unterminated block", "{\n  \"a\": 1 /* open *", true, "Unterminated block comment /* at 2:10"},
+		{"Single slash", "[1 / 2]", true, "Not a legal token / at 1:4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := internal.NewLexerWithOptions(tt.input, internal.ParseOptions{AllowComments: tt.allowComments})
+			err := l.ValidateTokens()
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("expected %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
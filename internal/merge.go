@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"fmt"
+)
+
+// Conflict is a path that both sides of a merge changed differently.
+type Conflict struct {
+	Path Pointer
+	// Base, Ours and Theirs are nil when the value doesn't exist on that side
+	Base, Ours, Theirs *Node
+}
+
+// String formats the conflict for humans.
+func (c Conflict) String() string {
+	return fmt.Sprintf(
+		"conflict at %q: base %s, ours %s, theirs %s",
+		c.Path.String(),
+		describe(c.Base),
+		describe(c.Ours),
+		describe(c.Theirs),
+	)
+}
+
+// Node returns the conflict as an object with the members path, base, ours
+// and theirs, the sides where the value doesn't exist are left out.
+func (c Conflict) Node() *Node {
+	n := NewObject()
+	n.Set("path", NewString(c.Path.String()))
+	for _, side := range []struct {
+		name string
+		v    *Node
+	}{{"base", c.Base}, {"ours", c.Ours}, {"theirs", c.Theirs}} {
+		if side.v != nil {
+			n.Set(side.name, side.v)
+		}
+	}
+	return n
+}
+
+func describe(n *Node) string {
+	if n == nil {
+		return "(missing)"
+	}
+	return n.String()
+}
+
+// Merge3 merges the changes ours and theirs made to base, object members are
+// merged per key path. Conflicting paths keep our value and are reported.
+func Merge3(base, ours, theirs *Node) (*Node, []Conflict) {
+	m := merger{}
+	return m.merge(base, ours, theirs, Pointer{}), m.conflicts
+}
+
+type merger struct {
+	conflicts []Conflict
+}
+
+// merge returns the merged value of a path, nil means it was removed.
+func (m *merger) merge(base, ours, theirs *Node, path Pointer) *Node {
+	switch {
+	case valuesEqual(ours, theirs), valuesEqual(base, theirs):
+		return ours
+	case valuesEqual(base, ours):
+		return theirs
+	}
+
+	if ours != nil && theirs != nil && ours.Kind == ObjectKind && theirs.Kind == ObjectKind {
+		if base == nil || base.Kind != ObjectKind {
+			base = NewObject()
+		}
+		return m.mergeObjects(base, ours, theirs, path)
+	}
+
+	m.conflicts = append(m.conflicts, Conflict{path, base, ours, theirs})
+	return ours
+}
+
+func (m *merger) mergeObjects(base, ours, theirs *Node, path Pointer) *Node {
+	result := NewObject()
+	seen := map[string]bool{}
+	add := func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		v := m.merge(base.Lookup(name), ours.Lookup(name), theirs.Lookup(name), path.Append(name))
+		if v != nil {
+			result.Set(name, v)
+		}
+	}
+
+	for _, mem := range ours.Members {
+		add(mem.Name)
+	}
+	for _, mem := range theirs.Members {
+		add(mem.Name)
+	}
+	return result
+}
@@ -0,0 +1,80 @@
+package internal_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name, base, ours, theirs, expected string
+		conflicts                          []string
+	}{
+		{
+			"No changes", `{"a": 1}`, `{"a": 1}`, `{"a": 1}`, `{"a":1}`, nil,
+		},
+		{
+			"Only theirs changed", `{"a": 1, "b": 2}`, `{"a": 1, "b": 2}`, `{"a": 1, "b": 3}`,
+			`{"a":1,"b":3}`, nil,
+		},
+		{
+			"Different keys", `{"a": 1, "b": 2}`, `{"a": 5, "b": 2}`, `{"a": 1, "b": 6}`,
+			`{"a":5,"b":6}`, nil,
+		},
+		{
+			"Nested keys", `{"db": {"host": "a", "port": 1}}`,
+			`{"db": {"host": "b", "port": 1}}`,
+			`{"db": {"host": "a", "port": 2, "user": "x"}}`,
+			`{"db":{"host":"b","port":2,"user":"x"}}`, nil,
+		},
+		{
+			"Both added different keys", `{}`, `{"a": 1}`, `{"b": 2}`, `{"a":1,"b":2}`, nil,
+		},
+		{
+			"Same change on both sides", `{"a": 1}`, `{"a": 2}`, `{"a": 2.0}`, `{"a":2}`, nil,
+		},
+		{
+			"Removed on one side", `{"a": 1, "b": 2}`, `{"b": 2}`, `{"a": 1, "b": 2, "c": 3}`,
+			`{"b":2,"c":3}`, nil,
+		},
+		{
+			"Conflicting values", `{"a": 1, "b": 1}`, `{"a": 2, "b": 1}`, `{"a": 3, "b": 5}`,
+			`{"a":2,"b":5}`, []string{`conflict at "/a": base 1, ours 2, theirs 3`},
+		},
+		{
+			"Removed and changed", `{"a": {"b": 1}}`, `{}`, `{"a": {"b": 2}}`,
+			`{}`, []string{`conflict at "/a": base {"b":1}, ours (missing), theirs {"b":2}`},
+		},
+		{
+			"Both added different values", `{}`, `{"a": [1]}`, `{"a": [2]}`,
+			`{"a":[1]}`, []string{`conflict at "/a": base (missing), ours [1], theirs [2]`},
+		},
+		{
+			"Arrays changed on both sides", `{"a": [1]}`, `{"a": [1, 2]}`, `{"a": [0, 1]}`,
+			`{"a":[1,2]}`, []string{`conflict at "/a": base [1], ours [1,2], theirs [0,1]`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, _ := internal.Parse(tt.base)
+			ours, _ := internal.Parse(tt.ours)
+			theirs, _ := internal.Parse(tt.theirs)
+
+			result, conflicts := internal.Merge3(base, ours, theirs)
+			if actual := result.String(); actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+
+			var actual []string
+			for _, c := range conflicts {
+				actual = append(actual, c.String())
+			}
+			if !slices.Equal(actual, tt.conflicts) {
+				t.Errorf("expected conflicts %q, got %q", tt.conflicts, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package internal
+
+import "fmt"
+
+// ApplyMergePatch applies a JSON Merge Patch (RFC 7396) to a copy of the
+// target and returns it. Members set to null in the patch are deleted.
+func ApplyMergePatch(target, patch *Node) *Node {
+	if patch.Kind != ObjectKind {
+		return patch.Clone()
+	}
+
+	result := NewObject()
+	if target != nil && target.Kind == ObjectKind {
+		result = target.Clone()
+	}
+
+	for _, m := range patch.Members {
+		if m.Value.Kind == NullKind {
+			result.Delete(m.Name)
+			continue
+		}
+		result.Set(m.Name, ApplyMergePatch(result.Lookup(m.Name), m.Value))
+	}
+	return result
+}
+
+// CreateMergePatch returns the smallest merge patch that turns src into dst.
+// Merge patches can't set a member to null, so it fails when dst holds a null
+// member that src doesn't.
+func CreateMergePatch(src, dst *Node) (*Node, error) {
+	return createMergePatch(src, dst, Pointer{})
+}
+
+func createMergePatch(src, dst *Node, path Pointer) (*Node, error) {
+	if dst.Kind != ObjectKind {
+		return dst.Clone(), nil
+	}
+	if src == nil || src.Kind != ObjectKind {
+		src = NewObject()
+	}
+
+	patch := NewObject()
+	for _, m := range src.Members {
+		if dst.Lookup(m.Name) == nil {
+			patch.Set(m.Name, NewNull())
+		}
+	}
+
+	for _, m := range dst.Members {
+		s := src.Lookup(m.Name)
+		if s != nil && s.Equal(m.Value) {
+			continue
+		}
+		if m.Value.Kind == NullKind {
+			return nil, fmt.Errorf(
+				"Merge patch can't set %q to null",
+				path.Append(m.Name).String(),
+			)
+		}
+
+		sub, err := createMergePatch(s, m.Value, path.Append(m.Name))
+		if err != nil {
+			return nil, err
+		}
+		patch.Set(m.Name, sub)
+	}
+	return patch, nil
+}
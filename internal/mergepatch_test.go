@@ -0,0 +1,84 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		target, patch, expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target+" "+tt.patch, func(t *testing.T) {
+			target, _ := internal.Parse(tt.target)
+			patch, _ := internal.Parse(tt.patch)
+
+			result := internal.ApplyMergePatch(target, patch)
+			if actual := result.String(); actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+			if target.String() != tt.target {
+				t.Errorf("target was modified: %v", target)
+			}
+		})
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	tests := []struct {
+		src, dst, expected string
+	}{
+		{`{"a":"b","c":{"d":1,"e":[1]}}`, `{"a":"b","c":{"d":1,"e":[1]}}`, `{}`},
+		{`{"a":"b","c":{"d":1,"e":[1]}}`, `{"a":"x","c":{"e":[1,2]}}`, `{"a":"x","c":{"d":null,"e":[1,2]}}`},
+		{`{"a":1}`, `{"a":{"b":2}}`, `{"a":{"b":2}}`},
+		{`{"a":1}`, `[1]`, `[1]`},
+		{`[1]`, `{"a":[null]}`, `{"a":[null]}`},
+		{`{"a":1.0}`, `{"a":1}`, `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src+" "+tt.dst, func(t *testing.T) {
+			src, _ := internal.Parse(tt.src)
+			dst, _ := internal.Parse(tt.dst)
+
+			patch, err := internal.CreateMergePatch(src, dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := patch.String(); actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+			if result := internal.ApplyMergePatch(src, patch); !result.Equal(dst) {
+				t.Errorf("applying the patch gave %v, expected %v", result, dst)
+			}
+		})
+	}
+}
+
+func TestCreateMergePatch_Null(t *testing.T) {
+	src, _ := internal.Parse(`{"a":{"b":1}}`)
+	dst, _ := internal.Parse(`{"a":{"b":null}}`)
+
+	if patch, err := internal.CreateMergePatch(src, dst); err == nil {
+		t.Errorf("expected an error, got %v", patch)
+	}
+}
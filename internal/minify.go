@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MinifyOptions changes what Minify rewrites besides whitespace.
+type MinifyOptions struct {
+	// ParseOptions says which input is accepted, trailing commas are only
+	// dropped when they are allowed
+	ParseOptions
+	// Strings rewrites strings and member names with the fewest escapes
+	Strings bool
+	// Numbers rewrites numbers in their shortest exact spelling, 1.50 becomes
+	// 1.5 and 1000000 becomes 1e6
+	Numbers bool
+}
+
+// Minify writes the tokens of a document without any insignificant
+// whitespace, comments or the trailing commas opts allows, checking the
+// structure of the document as it goes.
+func Minify(tokens []Token, opts MinifyOptions) ([]byte, error) {
+	if len(tokens) == 0 {
+		return nil, &SyntaxError{Code: CodeExpectedValue, msg: "Expected a value", Token: Token{Type: Illegal}}
+	}
+
+	f := formatter{builder: builder{tokens: tokens, trailingCommas: opts.trailingCommas()}, minify: opts, colon: ":"}
+	var b strings.Builder
+	if err := f.value(&b, ""); err != nil {
+		return nil, err
+	}
+	if t, ok := f.peek(); ok {
+		return nil, &SyntaxError{CodeTrailingData, "Expected the end of the document", t}
+	}
+	return []byte(b.String()), nil
+}
+
+// normalizeNumber returns the shortest literal with the exact value of the
+// number, plain notation wins ties with exponent notation.
+func normalizeNumber(t Token) string {
+	d, err := t.Decimal()
+	if err != nil {
+		return t.Literal
+	}
+	if d.Coefficient.Sign() == 0 {
+		return "0"
+	}
+
+	sign := ""
+	if d.Coefficient.Sign() < 0 {
+		sign = "-"
+	}
+	digits, exp := d.digits()
+	n := len(digits)
+
+	// the value is 0.digits times 10^exp
+	var plain int
+	switch {
+	case exp >= n:
+		plain = exp
+	case exp > 0:
+		plain = n + 1
+	default:
+		plain = n + 2 - exp
+	}
+
+	mantissa := digits[:1]
+	if n > 1 {
+		mantissa += "." + digits[1:]
+	}
+	scientific := mantissa + "e" + strconv.Itoa(exp-1)
+	if len(scientific) < plain {
+		return sign + scientific
+	}
+
+	switch {
+	case exp >= n:
+		return sign + digits + strings.Repeat("0", exp-n)
+	case exp > 0:
+		return sign + digits[:exp] + "." + digits[exp:]
+	default:
+		return sign + "0." + strings.Repeat("0", -exp) + digits
+	}
+}
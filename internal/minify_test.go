@@ -0,0 +1,71 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func TestMinify(t *testing.T) {
+	tests := []struct {
+		name, input string
+		opts        internal.MinifyOptions
+		expected    string
+	}{
+		{"Whitespace", "{\n  \"a\" : [ 1 , 2.50 ],\n  \"b\" : { }\n}", internal.MinifyOptions{}, `{"a":[1,2.50],"b":{}}`},
+		{"Keeps spelling", `["A\/", 1E+2]`, internal.MinifyOptions{}, `["A\/",1E+2]`},
+		{"Strings", `{"A": "é\/\n\u001F😀"}`, internal.MinifyOptions{Strings: true},
+			"{\"A\":\"é/\\n\\u001f😀\"}"},
+		{"Lone surrogate", `"\ud800"`, internal.MinifyOptions{Strings: true}, `"\ud800"`},
+		{"Numbers", `[1.50, -0.0, 1000000, 1E+2, 0.00012, 12.3e-1, 1234567e3, -5e-7]`, internal.MinifyOptions{Numbers: true},
+			`[1.5,0,1e6,100,1.2e-4,1.23,1234567000,-5e-7]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := internal.NewLexer(tt.input)
+			if err := l.ValidateTokens(); err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := internal.Minify(l.Tokens, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+
+			before, _ := internal.Parse(tt.input)
+			after, err := internal.Parse(string(actual))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !before.Equal(after) {
+				t.Errorf("%s doesn't equal %s", actual, tt.input)
+			}
+		})
+	}
+}
+
+func TestMinify_Invalid(t *testing.T) {
+	l := internal.NewLexer(`{"a": 1 "b": 2}`)
+	if err := l.ValidateTokens(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := internal.Minify(l.Tokens, internal.MinifyOptions{}); err == nil {
+		t.Error("expected an error for a missing comma")
+	}
+
+	l = internal.NewLexer(`[1,]`)
+	if err := l.ValidateTokens(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := internal.Minify(l.Tokens, internal.MinifyOptions{}); err == nil {
+		t.Error("expected an error for a trailing comma in strict mode")
+	}
+	lenient := internal.MinifyOptions{ParseOptions: internal.ParseOptions{AllowTrailingCommas: true}}
+	if out, err := internal.Minify(l.Tokens, lenient); err != nil || string(out) != "[1]" {
+		t.Errorf("expected [1] when trailing commas are allowed, got %s, %v", out, err)
+	}
+}
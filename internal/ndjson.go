@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Record is one document of a stream of documents.
+type Record struct {
+	// Line is the line the record starts on, starting at 1
+	Line int
+	// Offset is the byte offset the record starts at
+	Offset int
+	// Text is the record as it was read, without its delimiter
+	Text string
+	// Doc is the parsed document, it is nil when Err is set
+	Doc *Node
+	Err error
+	// Diagnostics describe Err and the warnings with their paths
+	Diagnostics []Diagnostic
+	Warnings    []Warning
+}
+
+// NDJSONReader reads newline delimited JSON, also known as JSON Lines, one
+// record per line. Blank lines are skipped and a line that isn't valid only
+// sets the Err of its record, reading goes on with the next line. Positions
+// in documents, errors and warnings point into the whole input.
+type NDJSONReader struct {
+	r      *bufio.Reader
+	opts   ParseOptions
+	line   int
+	offset int
+	rec    Record
+	err    error
+}
+
+// NewNDJSONReader creates a reader for the NDJSON in r.
+func NewNDJSONReader(r io.Reader, opts ParseOptions) *NDJSONReader {
+	return &NDJSONReader{r: bufio.NewReader(r), opts: opts, line: 1}
+}
+
+// Next reads the next record, it returns false at the end of the input or
+// when reading fails.
+func (n *NDJSONReader) Next() bool {
+	for n.err == nil {
+		text, err := n.r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			n.err = err
+			return false
+		}
+		if text == "" && err == io.EOF {
+			return false
+		}
+
+		line, offset := n.line, n.offset
+		n.line++
+		n.offset += len(text)
+		text = strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		n.rec = parseRecord(text, n.opts, Position{Offset: offset, Line: line, Column: 1})
+		return true
+	}
+	return false
+}
+
+// Record returns the record read by the last call to Next.
+func (n *NDJSONReader) Record() Record {
+	return n.rec
+}
+
+// Err returns the error that stopped reading, it is nil at the end of the
+// input. Records that aren't valid don't stop reading.
+func (n *NDJSONReader) Err() error {
+	return n.err
+}
+
+// parseRecord parses the text of a record that starts at the position in the
+// whole input.
+func parseRecord(text string, opts ParseOptions, start Position) Record {
+	rec := Record{Line: start.Line, Offset: start.Offset, Text: text}
+	if rec.Err = opts.Validate(); rec.Err != nil {
+		return rec
+	}
+
+	l := newLexerAt(text, opts, start)
+	if rec.Err = l.ValidateTokens(); rec.Err != nil {
+		rec.Diagnostics = []Diagnostic{Diagnose(rec.Err, l.Tokens)}
+		return rec
+	}
+	p := NewParserWithOptions(l.Tokens, opts)
+	rec.Doc, rec.Err = p.ParseDocument()
+	rec.Warnings = p.Warnings
+	if rec.Err != nil {
+		rec.Diagnostics = append(rec.Diagnostics, Diagnose(rec.Err, l.Tokens))
+	}
+	for _, w := range rec.Warnings {
+		rec.Diagnostics = append(rec.Diagnostics, DiagnoseWarning(w, l.Tokens))
+	}
+	return rec
+}
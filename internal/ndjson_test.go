@@ -0,0 +1,92 @@
+package internal_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func TestNDJSONReader(t *testing.T) {
+	input := "{\"id\": 1}\n" +
+		"\n" +
+		"[1, 2\r\n" +
+		"  \"text\"  \n" +
+		"{\"id\": 2} {\"id\": 3}\n" +
+		"null\n" +
+		"\"a\" \"b\"\n" +
+		"1, \"a\"\n" +
+		"true"
+	expected := []struct {
+		line, offset int
+		text, err    string
+	}{
+		{1, 0, `{"id": 1}`, ""},
+		{3, 11, `[1, 2`, "Length of the state should be 0. Instead got 1 at 3:6"},
+		{4, 18, `  "text"  `, ""},
+		{5, 29, `{"id": 2} {"id": 3}`, `Expected the end of the document at 5:11, got: "{"`},
+		{6, 49, `null`, ""},
+		{7, 54, `"a" "b"`, `Expected the end of the document at 7:5, got: "b"`},
+		{8, 62, `1, "a"`, `Expected the end of the document at 8:2, got: ","`},
+		{9, 69, `true`, ""},
+	}
+
+	r := internal.NewNDJSONReader(strings.NewReader(input), internal.ParseOptions{})
+	i := 0
+	for r.Next() {
+		if i >= len(expected) {
+			t.Fatalf("expected %d records, got more", len(expected))
+		}
+		rec, e := r.Record(), expected[i]
+		if rec.Line != e.line || rec.Offset != e.offset || rec.Text != e.text {
+			t.Errorf("expected line %d offset %d %q, got line %d offset %d %q",
+				e.line, e.offset, e.text, rec.Line, rec.Offset, rec.Text)
+		}
+		if e.err == "" && (rec.Err != nil || rec.Doc == nil) {
+			t.Errorf("expected line %d to be valid, got %v", e.line, rec.Err)
+		} else if e.err != "" && (rec.Err == nil || rec.Err.Error() != e.err) {
+			t.Errorf("expected %q, got %v", e.err, rec.Err)
+		}
+		i++
+	}
+	if r.Err() != nil {
+		t.Fatal(r.Err())
+	}
+	if i != len(expected) {
+		t.Errorf("expected %d records, got %d", len(expected), i)
+	}
+}
+
+func TestNDJSONReader_Positions(t *testing.T) {
+	input := "{}\n{\"big\": 9007199254740993}\n"
+	r := internal.NewNDJSONReader(strings.NewReader(input), internal.ParseOptions{WarnUnsafeIntegers: true})
+
+	var records []internal.Record
+	for r.Next() {
+		records = append(records, r.Record())
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %v", records)
+	}
+
+	expected := internal.Position{Offset: 11, Line: 2, Column: 9}
+	if w := records[1].Warnings; len(w) != 1 || w[0].Token.Pos != expected {
+		t.Errorf("expected a warning at %v, got %v", expected, w)
+	}
+	if pos := records[1].Doc.Lookup("big").Token.Pos; pos != expected {
+		t.Errorf("expected the number at %v, got %v", expected, pos)
+	}
+}
+
+func TestNDJSONReader_ReadError(t *testing.T) {
+	boom := errors.New("boom")
+	r := internal.NewNDJSONReader(iotest.ErrReader(boom), internal.ParseOptions{})
+	if r.Next() {
+		t.Error("expected no records")
+	}
+	if !errors.Is(r.Err(), boom) {
+		t.Errorf("expected %v, got %v", boom, r.Err())
+	}
+}
@@ -0,0 +1,279 @@
+package internal
+
+import (
+	"cmp"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MaxSafeInteger is the largest integer a float64 can hold without losing precision,
+// which is the largest integer JavaScript consumers can safely read.
+const MaxSafeInteger = 1<<53 - 1
+
+// maxIntegerExponent stops literals like 1e999999999 from being expanded into huge integers.
+const maxIntegerExponent = 10000
+
+// NumberError holds the error for when a number can't be converted
+type NumberError struct {
+	msg, arg string
+	// Err is strconv.ErrSyntax when the literal isn't a valid number and
+	// strconv.ErrRange when it doesn't fit in the requested type.
+	Err error
+}
+
+func (n *NumberError) Error() string {
+	return fmt.Sprintf("%s %s", n.msg, n.arg)
+}
+
+func (n *NumberError) Unwrap() error {
+	return n.Err
+}
+
+// Decimal is an exact decimal number, its value is Coefficient * 10^Exponent.
+type Decimal struct {
+	Coefficient *big.Int
+	Exponent    int
+}
+
+// String formats the decimal in JSON number notation.
+func (d Decimal) String() string {
+	s := d.Coefficient.String()
+	if d.Exponent == 0 {
+		return s
+	}
+	return s + "e" + strconv.Itoa(d.Exponent)
+}
+
+// Rat returns the decimal as a rational number. It builds 10^Exponent, so the
+// exponent should be checked first for numbers that aren't trusted.
+func (d Decimal) Rat() *big.Rat {
+	r := new(big.Rat).SetInt(d.Coefficient)
+	if d.Coefficient.Sign() == 0 {
+		return r
+	}
+	return scale(r, d.Exponent)
+}
+
+// scale multiplies r by 10^exp.
+func scale(r *big.Rat, exp int) *big.Rat {
+	if exp == 0 {
+		return r
+	}
+
+	p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exp))), nil)
+	if exp > 0 {
+		return r.Mul(r, new(big.Rat).SetInt(p))
+	}
+	return r.Quo(r, new(big.Rat).SetInt(p))
+}
+
+// IsInteger reports whether the decimal has no fractional part, it only
+// counts digits and so is cheap for any exponent.
+func (d Decimal) IsInteger() bool {
+	if d.Exponent >= 0 || d.Coefficient.Sign() == 0 {
+		return true
+	}
+	s := d.Coefficient.String()
+	zeros := len(s) - len(strings.TrimRight(s, "0"))
+	return d.Exponent+zeros >= 0
+}
+
+// Cmp compares the decimals by value, it returns -1, 0 or +1 like big.Int.Cmp.
+func (d Decimal) Cmp(o Decimal) int {
+	sign := d.Coefficient.Sign()
+	if s := o.Coefficient.Sign(); sign != s {
+		return cmp.Compare(sign, s)
+	} else if sign == 0 {
+		return 0
+	}
+
+	a, aExp := d.digits()
+	b, bExp := o.digits()
+	if aExp != bExp {
+		return cmp.Compare(aExp, bExp) * sign
+	}
+	// Both digit strings start at the same power of ten and have no trailing zeros.
+	return strings.Compare(a, b) * sign
+}
+
+// digits returns the absolute coefficient without trailing zeros and the
+// exponent of its first digit.
+func (d Decimal) digits() (string, int) {
+	s := new(big.Int).Abs(d.Coefficient).String()
+	t := strings.TrimRight(s, "0")
+	return t, d.Exponent + len(s)
+}
+
+// Int64 returns the number as an int64.
+func (t Token) Int64() (int64, error) {
+	i, err := t.BigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !i.IsInt64() {
+		return 0, &NumberError{"Number overflows int64", t.Literal, strconv.ErrRange}
+	}
+	return i.Int64(), nil
+}
+
+// Uint64 returns the number as an uint64.
+func (t Token) Uint64() (uint64, error) {
+	i, err := t.BigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !i.IsUint64() {
+		return 0, &NumberError{"Number overflows uint64", t.Literal, strconv.ErrRange}
+	}
+	return i.Uint64(), nil
+}
+
+// Float64 returns the nearest float64 to the number, it fails if the number
+// is too big to be finite.
+func (t Token) Float64() (float64, error) {
+	if !isNumberLiteral(t.Literal) {
+		return 0, &NumberError{"Not a valid number", t.Literal, strconv.ErrSyntax}
+	}
+
+	f, err := strconv.ParseFloat(t.Literal, 64)
+	if err != nil {
+		return 0, &NumberError{"Number overflows float64", t.Literal, strconv.ErrRange}
+	}
+	return f, nil
+}
+
+// BigInt returns the number as a big.Int, it fails if the number has a fractional part.
+func (t Token) BigInt() (*big.Int, error) {
+	d, err := t.Decimal()
+	if err != nil {
+		return nil, err
+	}
+
+	if !d.IsInteger() {
+		return nil, &NumberError{"Number is not an integer", t.Literal, strconv.ErrSyntax}
+	}
+	if d.Exponent > maxIntegerExponent {
+		return nil, &NumberError{"Number is too big", t.Literal, strconv.ErrRange}
+	}
+
+	// an integer with a negative exponent has at least as many trailing zeros,
+	// so 10^-Exponent is no longer than the literal
+	return d.Rat().Num(), nil
+}
+
+// BigFloat returns the number as a big.Float with the given precision in bits,
+// a precision of 0 uses the precision needed to hold the coefficient.
+func (t Token) BigFloat(prec uint) (*big.Float, error) {
+	if !isNumberLiteral(t.Literal) {
+		return nil, &NumberError{"Not a valid number", t.Literal, strconv.ErrSyntax}
+	}
+
+	if prec == 0 {
+		d, err := t.Decimal()
+		if err != nil {
+			return nil, err
+		}
+		prec = max(uint(d.Coefficient.BitLen()), 64)
+	}
+
+	f, _, err := big.ParseFloat(t.Literal, 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, &NumberError{"Number overflows big.Float", t.Literal, strconv.ErrRange}
+	}
+	return f, nil
+}
+
+// Decimal returns the exact decimal value of the number.
+func (t Token) Decimal() (Decimal, error) {
+	lit := t.Literal
+	if !isNumberLiteral(lit) {
+		return Decimal{}, &NumberError{"Not a valid number", lit, strconv.ErrSyntax}
+	}
+
+	exp := 0
+	if i := strings.IndexAny(lit, "eE"); i >= 0 {
+		e, err := strconv.Atoi(lit[i+1:])
+		if err != nil {
+			return Decimal{}, &NumberError{"Exponent overflows", lit, strconv.ErrRange}
+		}
+		exp = e
+		lit = lit[:i]
+	}
+
+	if i := strings.IndexByte(lit, '.'); i >= 0 {
+		exp -= len(lit) - i - 1
+		lit = lit[:i] + lit[i+1:]
+	}
+
+	c, _ := new(big.Int).SetString(lit, 10)
+	return Decimal{Coefficient: c, Exponent: exp}, nil
+}
+
+// IsUnsafeInteger reports whether the number is written as an integer that is
+// bigger than MaxSafeInteger, meaning JavaScript would silently round it.
+func (t Token) IsUnsafeInteger() bool {
+	if t.Type != Number || strings.ContainsAny(t.Literal, ".eE") {
+		return false
+	}
+
+	i, err := t.BigInt()
+	if err != nil {
+		return false
+	}
+	return i.CmpAbs(big.NewInt(MaxSafeInteger)) > 0
+}
+
+// isNumberLiteral checks the literal against the number grammar of RFC 8259.
+func isNumberLiteral(lit string) bool {
+	i := 0
+	if i < len(lit) && lit[i] == '-' {
+		i++
+	}
+
+	switch {
+	case i < len(lit) && lit[i] == '0':
+		i++
+	case i < len(lit) && lit[i] >= '1' && lit[i] <= '9':
+		for i < len(lit) && lit[i] >= '0' && lit[i] <= '9' {
+			i++
+		}
+	default:
+		return false
+	}
+
+	if i < len(lit) && lit[i] == '.' {
+		i++
+		start := i
+		for i < len(lit) && lit[i] >= '0' && lit[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return false
+		}
+	}
+
+	if i < len(lit) && (lit[i] == 'e' || lit[i] == 'E') {
+		i++
+		if i < len(lit) && (lit[i] == '+' || lit[i] == '-') {
+			i++
+		}
+		start := i
+		for i < len(lit) && lit[i] >= '0' && lit[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return false
+		}
+	}
+
+	return i == len(lit)
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
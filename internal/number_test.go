@@ -0,0 +1,157 @@
+package internal_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func TestNumber_Int64(t *testing.T) {
+	tests := []struct {
+		name, input string
+		expected    int64
+		err         error
+	}{
+		{"Integer", "42", 42, nil},
+		{"Negative integer", "-42", -42, nil},
+		{"Integral exponent", "1.5e1", 15, nil},
+		{"Max int64", "9223372036854775807", 9223372036854775807, nil},
+		{"Overflow", "9223372036854775808", 0, strconv.ErrRange},
+		{"Fraction", "1.5", 0, strconv.ErrSyntax},
+		{"Invalid literal", "1.2.3", 0, strconv.ErrSyntax},
+		{"Huge exponent", "1e999999", 0, strconv.ErrRange},
+		{"Tiny exponent", "1e-100000000", 0, strconv.ErrSyntax},
+		{"Tiny exponent of zero", "0e-100000000", 0, nil},
+		{"Trailing zeros", "4200e-2", 42, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := internal.Token{Type: internal.Number, Literal: tt.input}.Int64()
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNumber_Uint64(t *testing.T) {
+	tests := []struct {
+		name, input string
+		expected    uint64
+		err         error
+	}{
+		{"Max uint64", "18446744073709551615", 18446744073709551615, nil},
+		{"Overflow", "18446744073709551616", 0, strconv.ErrRange},
+		{"Negative", "-1", 0, strconv.ErrRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := internal.Token{Type: internal.Number, Literal: tt.input}.Uint64()
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNumber_Float64(t *testing.T) {
+	tests := []struct {
+		name, input string
+		expected    float64
+		err         error
+	}{
+		{"Fraction", "-1.5e-3", -0.0015, nil},
+		{"Rounded", "9007199254740993", 9007199254740992, nil},
+		{"Underflow", "1e-400", 0, nil},
+		{"Overflow", "1e400", 0, strconv.ErrRange},
+		{"Invalid literal", "01", 0, strconv.ErrSyntax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := internal.Token{Type: internal.Number, Literal: tt.input}.Float64()
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNumber_Exact(t *testing.T) {
+	tests := []struct {
+		name, input, bigInt, decimal, bigFloat string
+	}{
+		{"Big integer", "123456789012345678901234567890", "123456789012345678901234567890", "123456789012345678901234567890", "123456789012345678901234567890"},
+		{"Fraction", "-0.125", "", "-125e-3", "-0.125"},
+		{"Exponent", "25E+2", "2500", "25e2", "2500"},
+		{"Negative exponent", "1e-2", "", "1e-2", "0.01"},
+		{"Exponent overflow", "1e99999999999999999999", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tkn := internal.Token{Type: internal.Number, Literal: tt.input}
+
+			i, err := tkn.BigInt()
+			if tt.bigInt == "" && err == nil {
+				t.Errorf("expected an error, got %v", i)
+			} else if tt.bigInt != "" && (err != nil || i.String() != tt.bigInt) {
+				t.Errorf("expected %v, got %v (%v)", tt.bigInt, i, err)
+			}
+
+			d, err := tkn.Decimal()
+			if tt.decimal == "" && !errors.Is(err, strconv.ErrRange) {
+				t.Errorf("expected %v, got %v", strconv.ErrRange, err)
+			} else if tt.decimal != "" && (err != nil || d.String() != tt.decimal) {
+				t.Errorf("expected %v, got %v (%v)", tt.decimal, d, err)
+			}
+
+			f, err := tkn.BigFloat(0)
+			if tt.bigFloat == "" && !errors.Is(err, strconv.ErrRange) {
+				t.Errorf("expected %v, got %v", strconv.ErrRange, err)
+			} else if tt.bigFloat != "" && (err != nil || f.Text('f', -1) != tt.bigFloat) {
+				t.Errorf("expected %v, got %v (%v)", tt.bigFloat, f, err)
+			}
+		})
+	}
+}
+
+func TestParseTokens_UnsafeIntegers(t *testing.T) {
+	tests := []struct {
+		name, input string
+		warnings    int
+	}{
+		{"Safe integer", `{"id": 9007199254740991}`, 0},
+		{"Unsafe integer", `{"id": 9007199254740993}`, 1},
+		{"Unsafe negative integer", `[-9007199254740993, 1]`, 1},
+		{"Float notation", `{"id": 9007199254740993.0}`, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := internal.NewLexer(tt.input)
+			l.ValidateTokens()
+
+			p := internal.NewParserWithOptions(l.Tokens, internal.ParseOptions{WarnUnsafeIntegers: true})
+			if _, err := p.ParseTokens(); err != nil {
+				t.Fatal(err)
+			}
+			if len(p.Warnings) != tt.warnings {
+				t.Errorf("expected %d warnings, got %v", tt.warnings, p.Warnings)
+			}
+		})
+	}
+}
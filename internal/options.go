@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect is a string.
+type Dialect string
+
+const (
+	// DialectJSON is JSON as defined by RFC 8259
+	DialectJSON Dialect = "json"
+	// DialectJSON5 is JSON5, which adds comments, trailing commas, identifier
+	// keys, single quoted strings and more numbers to JSON
+	DialectJSON5 Dialect = "json5"
+)
+
+// DuplicateKeys is a string.
+type DuplicateKeys string
+
+const (
+	// AllowDuplicates keeps every member, Lookup returns the last one
+	AllowDuplicates DuplicateKeys = "allow"
+	// WarnDuplicates keeps every member and adds a warning for each duplicate
+	WarnDuplicates DuplicateKeys = "warn"
+	// RejectDuplicates fails on the second member with the same name
+	RejectDuplicates DuplicateKeys = "reject"
+)
+
+// ParseOptions configures the lexer and the parser, the zero value reads
+// RFC 8259 JSON.
+type ParseOptions struct {
+	// Dialect is DialectJSON when empty
+	Dialect Dialect
+	// AllowComments accepts // and /* */ comments, JSON5 always does
+	AllowComments bool
+	// AllowTrailingCommas accepts a single comma right before } or ], JSON5
+	// always does
+	AllowTrailingCommas bool
+	// WarnTrailingCommas accepts trailing commas and adds a warning for each
+	WarnTrailingCommas bool
+	// MaxDepth is how deep objects and arrays may nest, 0 means no limit
+	MaxDepth int
+	// DuplicateKeys is AllowDuplicates when empty
+	DuplicateKeys DuplicateKeys
+	// WarnUnsafeIntegers adds a warning for every integer that JavaScript
+	// can't represent exactly
+	WarnUnsafeIntegers bool
+}
+
+var (
+	// Strict reads RFC 8259 JSON without duplicate member names, as I-JSON
+	// requires, and with a nesting limit that is safe for untrusted input
+	Strict = ParseOptions{Dialect: DialectJSON, DuplicateKeys: RejectDuplicates, MaxDepth: 1000}
+	// RFC8259 reads exactly the grammar of RFC 8259
+	RFC8259 = ParseOptions{Dialect: DialectJSON, DuplicateKeys: AllowDuplicates}
+	// JSONC reads JSON with comments and trailing commas, like tsconfig.json
+	// and VS Code settings
+	JSONC = ParseOptions{Dialect: DialectJSON, AllowComments: true, AllowTrailingCommas: true}
+	// JSON5 reads the JSON5 dialect
+	JSON5 = ParseOptions{Dialect: DialectJSON5, DuplicateKeys: AllowDuplicates}
+	// Permissive reads everything this package understands
+	Permissive = ParseOptions{Dialect: DialectJSON5, AllowComments: true, AllowTrailingCommas: true}
+)
+
+// Presets holds the named presets by their lowercase name.
+var Presets = map[string]ParseOptions{
+	"strict":     Strict,
+	"rfc8259":    RFC8259,
+	"jsonc":      JSONC,
+	"json5":      JSON5,
+	"permissive": Permissive,
+}
+
+// OptionsError holds the error for when options aren't valid
+type OptionsError struct {
+	msg, arg string
+}
+
+func (o *OptionsError) Error() string {
+	return fmt.Sprintf("%s %q", o.msg, o.arg)
+}
+
+// Preset returns the preset with the name, ignoring case.
+func Preset(name string) (ParseOptions, error) {
+	opts, ok := Presets[strings.ToLower(name)]
+	if !ok {
+		return ParseOptions{}, &OptionsError{"Unknown preset", name}
+	}
+	return opts, nil
+}
+
+// Validate checks that the options have known values.
+func (o ParseOptions) Validate() error {
+	switch o.Dialect {
+	case "", DialectJSON, DialectJSON5:
+	default:
+		return &OptionsError{"Unknown dialect", string(o.Dialect)}
+	}
+	switch o.DuplicateKeys {
+	case "", AllowDuplicates, WarnDuplicates, RejectDuplicates:
+	default:
+		return &OptionsError{"Unknown duplicate keys mode", string(o.DuplicateKeys)}
+	}
+	if o.MaxDepth < 0 {
+		return &OptionsError{"MaxDepth can't be negative, got", fmt.Sprint(o.MaxDepth)}
+	}
+	return nil
+}
+
+func (o ParseOptions) json5() bool {
+	return o.Dialect == DialectJSON5
+}
+
+func (o ParseOptions) comments() bool {
+	return o.AllowComments || o.json5()
+}
+
+func (o ParseOptions) trailingCommas() bool {
+	return o.AllowTrailingCommas || o.WarnTrailingCommas || o.json5()
+}
@@ -0,0 +1,167 @@
+package internal_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func TestParseWithOptions_Presets(t *testing.T) {
+	inputs := map[string]string{
+		"plain":           `{"a": [1, 2]}`,
+		"duplicates":      `{"a": 1, "a": 2}`,
+		"comments":        "{\"a\": 1 // one\n}",
+		"trailing commas": `{"a": [1,],}`,
+		"json5":           `{a: 'b', c: +Infinity}`,
+		"deep":            strings.Repeat("[", 1001) + strings.Repeat("]", 1001),
+	}
+	tests := []struct {
+		preset string
+		valid  []string
+	}{
+		{"strict", []string{"plain"}},
+		{"rfc8259", []string{"plain", "duplicates", "deep"}},
+		{"JSONC", []string{"plain", "duplicates", "comments", "trailing commas", "deep"}},
+		{"json5", []string{"plain", "duplicates", "comments", "trailing commas", "json5", "deep"}},
+		{"permissive", []string{"plain", "duplicates", "comments", "trailing commas", "json5", "deep"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			opts, err := internal.Preset(tt.preset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, input := range inputs {
+				valid := false
+				for _, v := range tt.valid {
+					valid = valid || v == name
+				}
+				if _, err := internal.ParseWithOptions(input, opts); (err == nil) != valid {
+					t.Errorf("expected %s to be valid: %v, got %v", name, valid, err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     internal.ParseOptions
+		expected string
+	}{
+		{"Zero value", internal.ParseOptions{}, ""},
+		{"Dialect", internal.ParseOptions{Dialect: "yaml"}, `Unknown dialect "yaml"`},
+		{"Duplicate keys", internal.ParseOptions{DuplicateKeys: "first"}, `Unknown duplicate keys mode "first"`},
+		{"Max depth", internal.ParseOptions{MaxDepth: -1}, `MaxDepth can't be negative, got "-1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+			} else if err == nil || err.Error() != tt.expected {
+				t.Errorf("expected %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	if _, err := internal.Preset("yaml"); err == nil {
+		t.Error("expected an error for an unknown preset")
+	}
+}
+
+func TestParseOptions_MaxDepth(t *testing.T) {
+	opts := internal.ParseOptions{MaxDepth: 2}
+
+	l := internal.NewLexerWithOptions(`{"a": [1]}`, opts)
+	if err := l.ValidateTokens(); err != nil {
+		t.Fatal(err)
+	}
+
+	l = internal.NewLexerWithOptions(`{"a": [{}]}`, opts)
+	expected := "Nesting is deeper than the limit of 2 at 1:8"
+	if err := l.ValidateTokens(); err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+
+	// tokens from a lexer without the limit are still checked by the parser
+	l = internal.NewLexer(`[[[]]]`)
+	if err := l.ValidateTokens(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := internal.NewParserWithOptions(l.Tokens, opts).ParseTokens(); ok {
+		t.Error("expected ParseTokens to check the depth")
+	}
+	expected = `Nesting is deeper than the limit of 2 at 1:3, got: "["`
+	if _, err := internal.NewParserWithOptions(l.Tokens, opts).ParseDocument(); err == nil ||
+		err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+}
+
+func TestParseOptions_DeepNesting(t *testing.T) {
+	// a million levels would overflow the stack of the recursive parser
+	input := strings.Repeat("[", 1_000_000)
+	opts := internal.ParseOptions{MaxDepth: 10000}
+
+	_, err := internal.ParseWithOptions(input, opts)
+	if d := internal.Diagnose(err, nil); d.Code != internal.CodeTooDeep {
+		t.Errorf("expected %s, got %v", internal.CodeTooDeep, err)
+	}
+
+	// the parser stops at the limit too when the lexer had none
+	l := internal.NewLexer(input)
+	l.ValidateTokens()
+	_, err = internal.NewParserWithOptions(l.Tokens, opts).ParseDocument()
+	if d := internal.Diagnose(err, nil); d.Code != internal.CodeTooDeep {
+		t.Errorf("expected %s, got %v", internal.CodeTooDeep, err)
+	}
+}
+
+func TestParseOptions_DuplicateKeys(t *testing.T) {
+	input := `{"a": 1, "b": {"a": 2}, "a": 3, "a": 4}`
+	tests := []struct {
+		mode     internal.DuplicateKeys
+		valid    bool
+		warnings int
+	}{
+		{"", true, 0},
+		{internal.AllowDuplicates, true, 0},
+		{internal.WarnDuplicates, true, 2},
+		{internal.RejectDuplicates, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			l := internal.NewLexer(input)
+			if err := l.ValidateTokens(); err != nil {
+				t.Fatal(err)
+			}
+
+			p := internal.NewParserWithOptions(l.Tokens, internal.ParseOptions{DuplicateKeys: tt.mode})
+			if ok, err := p.ParseTokens(); ok != tt.valid {
+				t.Errorf("expected ParseTokens to return %v, got %v", tt.valid, err)
+			}
+			if len(p.Warnings) != tt.warnings {
+				t.Errorf("expected %d warnings from ParseTokens, got %v", tt.warnings, p.Warnings)
+			}
+
+			doc, err := p.ParseDocument()
+			if (err == nil) != tt.valid {
+				t.Fatalf("expected valid: %v, got %v", tt.valid, err)
+			}
+			if len(p.Warnings) != tt.warnings {
+				t.Errorf("expected %d warnings, got %v", tt.warnings, p.Warnings)
+			}
+			if doc != nil && doc.Lookup("a").Token.Literal != "4" {
+				t.Errorf("expected the last member to win, got %v", doc.Lookup("a"))
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ParallelOptions configures ValidateNDJSON.
+type ParallelOptions struct {
+	ParseOptions
+	// Workers is how many batches are parsed at once, 0 uses GOMAXPROCS
+	Workers int
+	// BatchSize is about how many bytes of whole lines go into one batch, 0
+	// uses 1 MiB
+	BatchSize int
+}
+
+// batch is a run of whole lines of the input.
+type batch struct {
+	seq          int
+	text         string
+	line, offset int
+	records      []Record
+	// err is a panic while parsing, it comes after the records
+	err error
+}
+
+// PanicError is a panic in ValidateNDJSON, it is returned as the error so one
+// bad batch doesn't take down the process.
+type PanicError struct {
+	Value any
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("internal error: %v", p.Value)
+}
+
+// ValidateNDJSON reads NDJSON from r and parses it with a pool of workers,
+// calling fn with every record in input order, the same records an
+// NDJSONReader returns. It stops when fn returns an error, when ctx is done
+// or when reading fails and returns that error. A panic while reading or
+// parsing is returned as a PanicError after the records before it. A Read on
+// r that blocks isn't interrupted by ctx.
+func ValidateNDJSON(ctx context.Context, r io.Reader, opts ParallelOptions, fn func(Record) error) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	size := opts.BatchSize
+	if size <= 0 {
+		size = 1 << 20
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan batch)
+	results := make(chan batch)
+	// inFlight limits the batches that are read but not handed to fn yet, so
+	// one slow batch doesn't pile up the ones after it
+	inFlight := make(chan struct{}, 2*workers)
+	readErr := make(chan error, 1)
+
+	go func() {
+		defer close(jobs)
+		defer func() {
+			if r := recover(); r != nil {
+				readErr <- &PanicError{r}
+			}
+		}()
+		readErr <- readBatches(ctx, r, size, inFlight, jobs)
+	}()
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				var b batch
+				var ok bool
+				select {
+				case b, ok = <-jobs:
+					if !ok {
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+
+				b.records, b.err = parseBatch(b, opts.ParseOptions)
+				select {
+				case results <- b:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var err error
+	pending := map[int]batch{}
+	next := 0
+	for b := range results {
+		if err != nil {
+			continue
+		}
+		pending[b.seq] = b
+		for p, ok := pending[next]; ok && err == nil; p, ok = pending[next] {
+			delete(pending, next)
+			next++
+			<-inFlight
+			for _, rec := range p.records {
+				if err = ctx.Err(); err == nil {
+					err = fn(rec)
+				}
+				if err != nil {
+					break
+				}
+			}
+			if err == nil {
+				err = p.err
+			}
+			if err != nil {
+				cancel()
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return <-readErr
+}
+
+// readBatches cuts the input into batches of whole lines and sends them to
+// jobs, waiting for room in inFlight before each one.
+func readBatches(ctx context.Context, r io.Reader, size int, inFlight chan struct{}, jobs chan<- batch) error {
+	br := bufio.NewReader(r)
+	line, offset := 1, 0
+	for seq := 0; ; seq++ {
+		buf := make([]byte, size)
+		n, err := io.ReadFull(br, buf)
+		buf = buf[:n]
+		switch err {
+		case nil:
+			// finish the last line
+			rest, err := br.ReadBytes('\n')
+			if err != nil && err != io.EOF {
+				return err
+			}
+			buf = append(buf, rest...)
+		case io.EOF, io.ErrUnexpectedEOF:
+		default:
+			return err
+		}
+		if len(buf) == 0 {
+			return nil
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		text := string(buf)
+		select {
+		case jobs <- batch{seq: seq, text: text, line: line, offset: offset}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		line += strings.Count(text, "\n")
+		offset += len(text)
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// parseBatch parses the lines of the batch like an NDJSONReader would, a
+// panic is returned as a PanicError with the records before it.
+func parseBatch(b batch, opts ParseOptions) (records []Record, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{r}
+		}
+	}()
+
+	n := &NDJSONReader{r: bufio.NewReader(strings.NewReader(b.text)), opts: opts, line: b.line, offset: b.offset}
+	for n.Next() {
+		records = append(records, n.Record())
+	}
+	return records, nil
+}
@@ -0,0 +1,131 @@
+package internal_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func ndjsonInput(lines int) string {
+	var b strings.Builder
+	for i := range lines {
+		switch i % 7 {
+		case 3:
+			fmt.Fprintf(&b, "{\"id\": %d,}\n", i)
+		case 5:
+			b.WriteString("\r\n")
+		default:
+			fmt.Fprintf(&b, "{\"id\": %d, \"tags\": [\"a\", \"b\"]}\n", i)
+		}
+	}
+	b.WriteString(`[1, 2`)
+	return b.String()
+}
+
+func TestValidateNDJSON(t *testing.T) {
+	input := ndjsonInput(500)
+
+	var expected []internal.Record
+	r := internal.NewNDJSONReader(strings.NewReader(input), internal.ParseOptions{})
+	for r.Next() {
+		expected = append(expected, r.Record())
+	}
+
+	tests := []struct {
+		name string
+		opts internal.ParallelOptions
+	}{
+		{"Defaults", internal.ParallelOptions{}},
+		{"One worker", internal.ParallelOptions{Workers: 1, BatchSize: 100}},
+		{"Small batches", internal.ParallelOptions{Workers: 8, BatchSize: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actual []internal.Record
+			err := internal.ValidateNDJSON(context.Background(), strings.NewReader(input), tt.opts,
+				func(rec internal.Record) error {
+					actual = append(actual, rec)
+					return nil
+				})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("expected the records of an NDJSONReader, got %d records", len(actual))
+			}
+		})
+	}
+}
+
+func TestValidateNDJSON_Stop(t *testing.T) {
+	input := ndjsonInput(1000)
+	opts := internal.ParallelOptions{Workers: 4, BatchSize: 64}
+
+	stop := errors.New("stop")
+	lines := 0
+	err := internal.ValidateNDJSON(context.Background(), strings.NewReader(input), opts,
+		func(rec internal.Record) error {
+			lines++
+			if rec.Err != nil {
+				return stop
+			}
+			return nil
+		})
+	if !errors.Is(err, stop) {
+		t.Errorf("expected %v, got %v", stop, err)
+	}
+	if lines != 4 {
+		t.Errorf("expected to stop at the first error after 4 records, got %d", lines)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lines = 0
+	err = internal.ValidateNDJSON(ctx, strings.NewReader(input), opts, func(internal.Record) error {
+		lines++
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+	if lines != 1 {
+		t.Errorf("expected no records after the cancel, got %d", lines)
+	}
+}
+
+// panicReader panics after its input runs out, like a buggy io.Reader.
+type panicReader struct {
+	r io.Reader
+}
+
+func (p *panicReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if err == io.EOF {
+		panic("read past the end")
+	}
+	return n, err
+}
+
+func TestValidateNDJSON_Panic(t *testing.T) {
+	r := &panicReader{strings.NewReader(ndjsonInput(100))}
+	lines := 0
+	err := internal.ValidateNDJSON(context.Background(), r, internal.ParallelOptions{Workers: 4, BatchSize: 64},
+		func(internal.Record) error {
+			lines++
+			return nil
+		})
+	var panicErr *internal.PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "read past the end" {
+		t.Fatalf("expected a PanicError, got %v", err)
+	}
+	if lines == 0 {
+		t.Error("expected the records of the batches before the panic")
+	}
+}
@@ -2,9 +2,27 @@ package internal
 
 import "fmt"
 
+// Warning holds something that is valid json but might still cause problems
+type Warning struct {
+	// Code names the kind of warning, like CodeUnsafeInteger
+	Code  string
+	Token Token
+	Msg   string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s %s", w.Msg, w.Token.Literal)
+}
+
 // Parser is used to parse the given tokens
 type Parser struct {
 	tokens []Token
+	// Options says which trailing commas, numbers and duplicate member names
+	// are accepted and what gets a warning. ParseDocument also checks MaxDepth
+	Options ParseOptions
+	// Warnings holds the warnings found by the last call to ParseTokens or
+	// ParseDocument
+	Warnings []Warning
 }
 
 // NewParser creates a new parser
@@ -14,18 +32,42 @@ func NewParser(t []Token) *Parser {
 	return p
 }
 
+// NewParserWithOptions creates a new parser that uses the options
+func NewParserWithOptions(t []Token, opts ParseOptions) *Parser {
+	p := NewParser(t)
+	p.Options = opts
+	return p
+}
+
 // ParseTokens loops through all the tokens to make sure it's valid
 func (p *Parser) ParseTokens() (bool, error) {
 	s := NewStack[TokenType]()
+	// names holds the member names of every open object
+	names := NewStack[map[string]bool]()
+	p.Warnings = nil
+
+	// comments can sit between any two tokens, so they are left out here
+	tokens := make([]Token, 0, len(p.tokens))
+	for _, t := range p.tokens {
+		if t.Type != Comment {
+			tokens = append(tokens, t)
+		}
+	}
+
+	for i, t := range tokens {
+		if (t.Type == OpeningCurly || t.Type == OpeningBracket) &&
+			p.Options.MaxDepth > 0 && len(s.state) >= p.Options.MaxDepth {
+			return false, &SyntaxError{CodeTooDeep, fmt.Sprintf("Nesting is deeper than the limit of %d", p.Options.MaxDepth), t}
+		}
 
-	for i, t := range p.tokens {
 		switch t.Type {
 		case OpeningCurly:
+			names.Push(map[string]bool{})
 			if i == 0 {
 				s.Push(OpeningCurly)
 				continue
 			}
-			pt := p.tokens[i-1]
+			pt := tokens[i-1]
 			if pt.Type != Colon &&
 				(t.State == InsideArray && pt.Type != Comma && pt.Type != OpeningBracket) {
 				return false, fmt.Errorf(
@@ -35,7 +77,10 @@ func (p *Parser) ParseTokens() (bool, error) {
 			}
 			s.Push(OpeningCurly)
 		case ClosingCurly:
-			prevTkn := p.tokens[i-1].Type
+			prevTkn := tokens[i-1].Type
+			if prevTkn == Comma && p.trailingComma(tokens, i) {
+				prevTkn = tokens[i-2].Type
+			}
 			if prevTkn != ValueString && prevTkn != Number && prevTkn != True && prevTkn != False &&
 				prevTkn != Null &&
 				prevTkn != ClosingCurly &&
@@ -50,12 +95,13 @@ func (p *Parser) ParseTokens() (bool, error) {
 			if state != OpeningCurly {
 				return false, fmt.Errorf("Unmatched curly braces")
 			}
+			names.Pop()
 		case OpeningBracket:
 			if i == 0 {
 				s.Push(OpeningBracket)
 				continue
 			}
-			pt := p.tokens[i-1]
+			pt := tokens[i-1]
 			if pt.Type != Colon && pt.Type != OpeningBracket && pt.Type != Comma {
 				return false, fmt.Errorf(
 					"NAME_SEPARATOR or LEFT_SQUARE_BRACKET should preceed LEFT_SQUARE_BRACKET, instead got: %v",
@@ -64,7 +110,10 @@ func (p *Parser) ParseTokens() (bool, error) {
 			}
 			s.Push(OpeningBracket)
 		case ClosingBracket:
-			prevTkn := p.tokens[i-1].Type
+			prevTkn := tokens[i-1].Type
+			if prevTkn == Comma && p.trailingComma(tokens, i) {
+				prevTkn = tokens[i-2].Type
+			}
 			if prevTkn != ValueString && prevTkn != Number && prevTkn != True && prevTkn != False &&
 				prevTkn != Null &&
 				prevTkn != ClosingCurly &&
@@ -80,15 +129,20 @@ func (p *Parser) ParseTokens() (bool, error) {
 				return false, fmt.Errorf("Unmatched brackets")
 			}
 		case NameString:
-			prevTkn := p.tokens[i-1]
+			prevTkn := tokens[i-1]
 			if prevTkn.Type != OpeningCurly && prevTkn.Type != Comma {
 				return false, fmt.Errorf(
 					"OpeningCurly or Comma should preceed String, instead got: %v",
 					prevTkn,
 				)
 			}
+			if !names.IsEmpty() {
+				if err := p.memberName(names.Peek(), t); err != nil {
+					return false, err
+				}
+			}
 		case ValueString:
-			prevTkn := p.tokens[i-1]
+			prevTkn := tokens[i-1]
 			if prevTkn.Type != Colon &&
 				(t.State == InsideArray && prevTkn.Type != OpeningBracket && prevTkn.Type != Comma) {
 				return false, fmt.Errorf(
@@ -97,7 +151,7 @@ func (p *Parser) ParseTokens() (bool, error) {
 				)
 			}
 		case Colon:
-			prevTkn := p.tokens[i-1].Type
+			prevTkn := tokens[i-1].Type
 			if prevTkn != NameString {
 				return false, fmt.Errorf(
 					"String should preceed NAME_SEPARATOR, instead got: %v",
@@ -105,7 +159,7 @@ func (p *Parser) ParseTokens() (bool, error) {
 				)
 			}
 		case Comma:
-			prevTkn := p.tokens[i-1].Type
+			prevTkn := tokens[i-1].Type
 			if t.State == Invalid {
 				return false, fmt.Errorf(
 					"VALUE_SEPARATOR should not come after OpeningBrace or  RIGHT_CURLY_BRACKET (when the object isn't nested, got: %v",
@@ -124,7 +178,7 @@ func (p *Parser) ParseTokens() (bool, error) {
 				)
 			}
 		case Number:
-			prevTkn := p.tokens[i-1].Type
+			prevTkn := tokens[i-1].Type
 			if prevTkn != Colon &&
 				(t.State == InsideArray && prevTkn != Comma && prevTkn != OpeningBracket) {
 				return false, fmt.Errorf(
@@ -132,8 +186,11 @@ func (p *Parser) ParseTokens() (bool, error) {
 					prevTkn,
 				)
 			}
+			if p.Options.WarnUnsafeIntegers && t.IsUnsafeInteger() {
+				p.Warnings = append(p.Warnings, Warning{CodeUnsafeInteger, t, "Integer is bigger than 2^53-1"})
+			}
 		case True, False, Null:
-			prevTkn := p.tokens[i-1]
+			prevTkn := tokens[i-1]
 			if (t.State == InsideObject && prevTkn.Type != Colon) ||
 				(t.State == InsideArray && prevTkn.Type != Comma && prevTkn.Type != OpeningBracket) {
 				return false, fmt.Errorf(
@@ -152,3 +209,35 @@ func (p *Parser) ParseTokens() (bool, error) {
 
 	return true, nil
 }
+
+// memberName records the name of a member, checking it against the names
+// already in the object when duplicates aren't allowed.
+func (p *Parser) memberName(names map[string]bool, t Token) error {
+	if p.Options.DuplicateKeys != WarnDuplicates && p.Options.DuplicateKeys != RejectDuplicates {
+		return nil
+	}
+
+	name, err := unquote(t.Literal)
+	if err != nil {
+		name = t.Literal
+	}
+	if names[name] && p.Options.DuplicateKeys == RejectDuplicates {
+		return &SyntaxError{CodeDuplicateKey, "Duplicate member name", t}
+	} else if names[name] {
+		p.Warnings = append(p.Warnings, Warning{CodeDuplicateKey, t, "Duplicate member name"})
+	}
+	names[name] = true
+	return nil
+}
+
+// trailingComma reports whether the comma before the i-th token may be
+// skipped, adding a warning for it when asked to.
+func (p *Parser) trailingComma(tokens []Token, i int) bool {
+	if !p.Options.trailingCommas() {
+		return false
+	}
+	if p.Options.WarnTrailingCommas {
+		p.Warnings = append(p.Warnings, Warning{CodeTrailingComma, tokens[i], "Trailing comma before"})
+	}
+	return true
+}
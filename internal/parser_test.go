@@ -275,3 +275,47 @@ func TestParseTokens_Invalid(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_TrailingCommas(t *testing.T) {
+	tests := []struct {
+		name, input string
+		allow, warn bool
+		valid       bool
+		warnings    int
+	}{
+		{"Rejected by default", `{"a": [1,],}`, false, false, false, 0},
+		{"Allowed", `{"a": [1,],}`, true, false, true, 0},
+		{"Warned", `{"a": [1,], "b": {"c": null,},}`, false, true, true, 3},
+		{"Only one comma", `[1,,]`, true, false, false, 0},
+		{"Not in empty containers", `[,]`, true, false, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := internal.NewLexer(tt.input)
+			if err := l.ValidateTokens(); err != nil {
+				t.Fatal(err)
+			}
+
+			p := internal.NewParserWithOptions(l.Tokens, internal.ParseOptions{
+				AllowTrailingCommas: tt.allow,
+				WarnTrailingCommas:  tt.warn,
+			})
+			ok, _ := p.ParseTokens()
+			if ok != tt.valid {
+				t.Errorf("expected ParseTokens to return %v, got %v", tt.valid, ok)
+			}
+			if len(p.Warnings) != tt.warnings {
+				t.Errorf("expected %d warnings from ParseTokens, got %v", tt.warnings, p.Warnings)
+			}
+
+			_, err := p.ParseDocument()
+			if (err == nil) != tt.valid {
+				t.Errorf("expected ParseDocument to be valid: %v, got %v", tt.valid, err)
+			}
+			if len(p.Warnings) != tt.warnings {
+				t.Errorf("expected %d warnings from ParseDocument, got %v", tt.warnings, p.Warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package internal
+
+import (
+	"fmt"
+)
+
+// PatchError holds the error for when a patch operation is invalid or fails
+type PatchError struct {
+	msg string
+	// Index is the position of the operation in the patch
+	Index int
+	Op    string
+}
+
+func (p *PatchError) Error() string {
+	if p.Index < 0 {
+		return p.msg
+	}
+	return fmt.Sprintf("Operation %d (%s) failed: %s", p.Index, p.Op, p.msg)
+}
+
+// Operation is a single JSON Patch operation, see RFC 6902.
+type Operation struct {
+	// Op is one of add, remove, replace, move, copy or test
+	Op   string
+	Path Pointer
+	// From is only used by move and copy
+	From Pointer
+	// Value is only used by add, replace and test
+	Value *Node
+}
+
+// ParsePatch reads the operations out of a parsed patch document.
+func ParsePatch(doc *Node) ([]Operation, error) {
+	if doc.Kind != ArrayKind {
+		return nil, &PatchError{"Patch should be an array of operations", -1, ""}
+	}
+
+	ops := make([]Operation, len(doc.Elements))
+	for i, e := range doc.Elements {
+		if e.Kind != ObjectKind {
+			return nil, &PatchError{"Operation should be an object", i, ""}
+		}
+
+		op := e.Lookup("op")
+		if op == nil || op.Kind != StringKind {
+			return nil, &PatchError{`Member "op" should be a string`, i, ""}
+		}
+		ops[i].Op = op.text
+
+		var err error
+		if ops[i].Path, err = patchPointer(e, "path", i, op.text); err != nil {
+			return nil, err
+		}
+
+		switch op.text {
+		case "add", "replace", "test":
+			if ops[i].Value = e.Lookup("value"); ops[i].Value == nil {
+				return nil, &PatchError{`Member "value" is missing`, i, op.text}
+			}
+		case "move", "copy":
+			if ops[i].From, err = patchPointer(e, "from", i, op.text); err != nil {
+				return nil, err
+			}
+		case "remove":
+		default:
+			return nil, &PatchError{"Unknown operation", i, op.text}
+		}
+	}
+	return ops, nil
+}
+
+func patchPointer(e *Node, name string, i int, op string) (Pointer, error) {
+	v := e.Lookup(name)
+	if v == nil || v.Kind != StringKind {
+		return nil, &PatchError{fmt.Sprintf("Member %q should be a string", name), i, op}
+	}
+
+	p, err := ParsePointer(v.text)
+	if err != nil {
+		return nil, &PatchError{err.Error(), i, op}
+	}
+	return p, nil
+}
+
+// ApplyPatch applies the operations to a copy of the document and returns it.
+// The operations are atomic, the document is never modified so a failing
+// operation leaves it untouched.
+func ApplyPatch(doc *Node, ops []Operation) (*Node, error) {
+	doc = doc.Clone()
+	for i, op := range ops {
+		var err error
+		if doc, err = applyOperation(doc, op); err != nil {
+			return nil, &PatchError{err.Error(), i, op.Op}
+		}
+	}
+	return doc, nil
+}
+
+func applyOperation(doc *Node, op Operation) (*Node, error) {
+	switch op.Op {
+	case "add":
+		return patchAdd(doc, op.Path, op.Value.Clone())
+	case "remove":
+		_, err := patchRemove(doc, op.Path)
+		return doc, err
+	case "replace":
+		if _, err := op.Path.Evaluate(doc); err != nil {
+			return nil, err
+		}
+		if len(op.Path) == 0 {
+			return op.Value.Clone(), nil
+		}
+
+		parent, _ := op.Path[:len(op.Path)-1].Evaluate(doc)
+		last := op.Path[len(op.Path)-1]
+		if parent.Kind == ObjectKind {
+			parent.Set(last, op.Value.Clone())
+		} else {
+			i, _ := op.Path.index(len(op.Path)-1, len(parent.Elements))
+			parent.Elements[i] = op.Value.Clone()
+		}
+		return doc, nil
+	case "move":
+		if isProperPrefix(op.From, op.Path) {
+			return nil, fmt.Errorf("Can't move %q into one of its children", op.From)
+		}
+		if len(op.From) == 0 {
+			if len(op.Path) != 0 {
+				return nil, fmt.Errorf("Can't move the root")
+			}
+			return doc, nil
+		}
+		v, err := patchRemove(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, op.Path, v)
+	case "copy":
+		v, err := op.From.Evaluate(doc)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, op.Path, v.Clone())
+	case "test":
+		v, err := op.Path.Evaluate(doc)
+		if err != nil {
+			return nil, err
+		}
+		if !v.Equal(op.Value) {
+			return nil, fmt.Errorf("Value at %q is %v, expected %v", op.Path, v, op.Value)
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("Unknown operation")
+}
+
+// patchAdd adds the value at the pointer and returns the new root.
+func patchAdd(doc *Node, p Pointer, v *Node) (*Node, error) {
+	if len(p) == 0 {
+		return v, nil
+	}
+
+	parent, err := p[:len(p)-1].Evaluate(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	switch parent.Kind {
+	case ObjectKind:
+		parent.Set(p[len(p)-1], v)
+	case ArrayKind:
+		i, err := p.index(len(p)-1, len(parent.Elements))
+		if err != nil {
+			return nil, err
+		}
+		if i > len(parent.Elements) {
+			return nil, &PointerError{CodeIndexOutOfRange, "Array index out of range", p.String(), p[len(p)-1]}
+		}
+		parent.Insert(i, v)
+	default:
+		return nil, &PointerError{
+			CodePointerMismatch,
+			fmt.Sprintf("Can't add to a %s with segment", parent.Kind),
+			p.String(),
+			p[len(p)-1],
+		}
+	}
+	return doc, nil
+}
+
+// patchRemove removes the value at the pointer and returns it.
+func patchRemove(doc *Node, p Pointer) (*Node, error) {
+	if len(p) == 0 {
+		return nil, fmt.Errorf("Can't remove the root")
+	}
+
+	v, err := p.Evaluate(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, _ := p[:len(p)-1].Evaluate(doc)
+	if parent.Kind == ObjectKind {
+		parent.Delete(p[len(p)-1])
+	} else {
+		i, _ := p.index(len(p)-1, len(parent.Elements))
+		parent.Remove(i)
+	}
+	return v, nil
+}
+
+func isProperPrefix(prefix, p Pointer) bool {
+	if len(prefix) >= len(p) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != p[i] {
+			return false
+		}
+	}
+	return true
+}
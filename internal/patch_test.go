@@ -0,0 +1,143 @@
+package internal_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func applyPatch(t *testing.T, doc, patch string) (*internal.Node, *internal.Node, error) {
+	t.Helper()
+
+	d, err := internal.Parse(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := internal.Parse(patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ops, err := internal.ParsePatch(p)
+	if err != nil {
+		return d, nil, err
+	}
+	result, err := internal.ApplyPatch(d, ops)
+	return d, result, err
+}
+
+func TestApplyPatch_Valid(t *testing.T) {
+	tests := []struct {
+		name, doc, patch, expected string
+	}{
+		{
+			"Add object member", `{"foo": "bar"}`,
+			`[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			`{"foo":"bar","baz":"qux"}`,
+		},
+		{
+			"Add array element", `{"foo": ["bar", "baz"]}`,
+			`[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			`{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			"Append array element", `{"foo": [1]}`,
+			`[{"op": "add", "path": "/foo/-", "value": 2}]`,
+			`{"foo":[1,2]}`,
+		},
+		{
+			"Remove object member", `{"baz": "qux", "foo": "bar"}`,
+			`[{"op": "remove", "path": "/baz"}]`,
+			`{"foo":"bar"}`,
+		},
+		{
+			"Remove array element", `{"foo": ["bar", "qux", "baz"]}`,
+			`[{"op": "remove", "path": "/foo/1"}]`,
+			`{"foo":["bar","baz"]}`,
+		},
+		{
+			"Replace keeps member order", `{"baz": "qux", "foo": "bar"}`,
+			`[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			`{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			"Replace root", `{"a": 1}`,
+			`[{"op": "replace", "path": "", "value": [1]}]`,
+			`[1]`,
+		},
+		{
+			"Move member", `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			`[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			"Move array element", `{"foo": ["all", "grass", "cows", "eat"]}`,
+			`[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			`{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			"Copy", `{"a": {"b": [1]}}`,
+			`[{"op": "copy", "from": "/a", "path": "/c"}, {"op": "add", "path": "/c/b/-", "value": 2}]`,
+			`{"a":{"b":[1]},"c":{"b":[1,2]}}`,
+		},
+		{
+			"Test passes", `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			`[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2.0}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{
+			"Escaped pointer", `{"/": 9, "~1": 10}`,
+			`[{"op": "test", "path": "/~01", "value": 10}, {"op": "remove", "path": "/~1"}]`,
+			`{"~1":10}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, result, err := applyPatch(t, tt.doc, tt.patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := result.String(); actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestApplyPatch_Invalid(t *testing.T) {
+	tests := []struct {
+		name, doc, patch string
+		index            int
+	}{
+		{"Not an array", `{}`, `{"op": "add"}`, -1},
+		{"Unknown op", `{}`, `[{"op": "bad", "path": ""}]`, 0},
+		{"Missing value", `{}`, `[{"op": "add", "path": "/a"}]`, 0},
+		{"Missing parent", `{}`, `[{"op": "add", "path": "/a/b", "value": 1}]`, 0},
+		{"Index out of range", `[1]`, `[{"op": "add", "path": "/2", "value": 1}]`, 0},
+		{"Remove missing", `{"a": 1}`, `[{"op": "remove", "path": "/a"}, {"op": "remove", "path": "/a"}]`, 1},
+		{"Replace missing", `{}`, `[{"op": "replace", "path": "/a", "value": 1}]`, 0},
+		{"Move into child", `{"a": {}}`, `[{"op": "move", "from": "/a", "path": "/a/b"}]`, 0},
+		{"Failing test", `{"a": [1, 2]}`, `[{"op": "add", "path": "/a/-", "value": 3}, {"op": "test", "path": "/a/0", "value": "1"}]`, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, _, err := applyPatch(t, tt.doc, tt.patch)
+
+			var pErr *internal.PatchError
+			if !errors.As(err, &pErr) {
+				t.Fatalf("expected a PatchError, got %v", err)
+			}
+			if pErr.Index != tt.index {
+				t.Errorf("expected index %d, got %d (%v)", tt.index, pErr.Index, err)
+			}
+
+			original, _ := internal.Parse(tt.doc)
+			if doc.String() != original.String() {
+				t.Errorf("document was modified: %v", doc)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PointerError holds the error for when a pointer can't be parsed or resolved
+type PointerError struct {
+	// Code names the kind of error, like CodePointerNotFound
+	Code string
+	msg  string
+	// Pointer is the full pointer
+	Pointer string
+	// Segment is the reference token that failed
+	Segment string
+}
+
+func (p *PointerError) Error() string {
+	return fmt.Sprintf("%s %q in pointer %q", p.msg, p.Segment, p.Pointer)
+}
+
+// Pointer is a parsed JSON Pointer as defined by RFC 6901, it holds the
+// unescaped reference tokens.
+type Pointer []string
+
+// ParsePointer parses a pointer like "/users/3/email", the URI fragment form
+// "#/users/3/email" is accepted as well.
+func ParsePointer(s string) (Pointer, error) {
+	raw := s
+	if strings.HasPrefix(s, "#") {
+		u, err := url.PathUnescape(s[1:])
+		if err != nil {
+			return nil, &PointerError{CodeInvalidPointer, "Invalid percent encoding", raw, s}
+		}
+		s = u
+	}
+
+	if s == "" {
+		return Pointer{}, nil
+	}
+	if s[0] != '/' {
+		return nil, &PointerError{CodeInvalidPointer, "Pointer should start with a slash, instead got", raw, s}
+	}
+
+	segments := strings.Split(s[1:], "/")
+	p := make(Pointer, len(segments))
+	for i, seg := range segments {
+		for j := 0; j < len(seg); j++ {
+			if seg[j] == '~' && (j+1 >= len(seg) || (seg[j+1] != '0' && seg[j+1] != '1')) {
+				return nil, &PointerError{CodeInvalidPointer, "Invalid escape in segment", raw, seg}
+			}
+		}
+		p[i] = strings.ReplaceAll(strings.ReplaceAll(seg, "~1", "/"), "~0", "~")
+	}
+	return p, nil
+}
+
+// String returns the pointer with its reference tokens escaped.
+func (p Pointer) String() string {
+	var b strings.Builder
+	for _, seg := range p {
+		b.WriteByte('/')
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(seg, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+// Append returns a new pointer with the segments added to the end.
+func (p Pointer) Append(segments ...string) Pointer {
+	c := make(Pointer, 0, len(p)+len(segments))
+	return append(append(c, p...), segments...)
+}
+
+// Evaluate resolves the pointer against the document, the returned node
+// carries the position of its token in the source.
+func (p Pointer) Evaluate(doc *Node) (*Node, error) {
+	n := doc
+	for i, seg := range p {
+		next, err := p.step(n, i)
+		if err != nil {
+			return nil, err
+		}
+		if next == nil {
+			return nil, &PointerError{CodePointerNotFound, "Could not resolve segment", p.String(), seg}
+		}
+		n = next
+	}
+	return n, nil
+}
+
+// Lookup parses the pointer and resolves it against the document.
+func Lookup(doc *Node, pointer string) (*Node, error) {
+	p, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return p.Evaluate(doc)
+}
+
+// step resolves the i-th segment inside of n, it returns nil if the member or
+// element doesn't exist.
+func (p Pointer) step(n *Node, i int) (*Node, error) {
+	switch n.Kind {
+	case ObjectKind:
+		return n.Lookup(p[i]), nil
+	case ArrayKind:
+		idx, err := p.index(i, len(n.Elements))
+		if err != nil {
+			return nil, err
+		}
+		if idx >= len(n.Elements) {
+			return nil, nil
+		}
+		return n.Elements[idx], nil
+	}
+	return nil, &PointerError{
+		CodePointerMismatch,
+		fmt.Sprintf("Can't index into a %s with segment", n.Kind),
+		p.String(),
+		p[i],
+	}
+}
+
+// index parses the i-th segment as an array index, "-" is the index right
+// after the last element.
+func (p Pointer) index(i, length int) (int, error) {
+	seg := p[i]
+	if seg == "-" {
+		return length, nil
+	}
+	if seg == "" || (len(seg) > 1 && seg[0] == '0') || strings.TrimLeft(seg, "0123456789") != "" {
+		return 0, &PointerError{CodeInvalidIndex, "Invalid array index", p.String(), seg}
+	}
+
+	idx, err := strconv.Atoi(seg)
+	if err != nil {
+		return 0, &PointerError{CodeIndexOutOfRange, "Array index out of range", p.String(), seg}
+	}
+	return idx, nil
+}
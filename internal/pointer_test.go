@@ -0,0 +1,113 @@
+package internal_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+const pointerDoc = `{
+  "foo": ["bar", "baz"],
+  "": 0,
+  "a/b": 1,
+  "c%d": 2,
+  "e^f": 3,
+  "g|h": 4,
+  "i\\j": 5,
+  "k\"l": 6,
+  " ": 7,
+  "m~n": 8,
+  "users": [{"email": "a@example.com"}, {"email": "b@example.com"}],
+  "\u00e9\ud83d\ude00": 9
+}`
+
+func TestPointer_Evaluate(t *testing.T) {
+	tests := []struct {
+		pointer, expected string
+		line, column      int
+	}{
+		{"", "{", 1, 1},
+		{"/foo", "[", 2, 10},
+		{"/foo/0", "bar", 2, 11},
+		{"/", "0", 3, 7},
+		{"/a~1b", "1", 4, 10},
+		{"/c%d", "2", 5, 10},
+		{"/e^f", "3", 6, 10},
+		{"/g|h", "4", 7, 10},
+		{"/i\\j", "5", 8, 11},
+		{"/k\"l", "6", 9, 11},
+		{"/ ", "7", 10, 8},
+		{"/m~0n", "8", 11, 10},
+		{"/users/1/email", "b@example.com", 12, 51},
+		{"/é😀", "9", 13, 25},
+		{"#/a~1b", "1", 4, 10},
+		{"#/c%25d", "2", 5, 10},
+	}
+
+	doc, err := internal.Parse(pointerDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			n, err := internal.Lookup(doc, tt.pointer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n.Token.Literal != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, n.Token.Literal)
+			}
+			if n.Token.Pos.Line != tt.line || n.Token.Pos.Column != tt.column {
+				t.Errorf("expected %d:%d, got %v", tt.line, tt.column, n.Token.Pos)
+			}
+		})
+	}
+}
+
+func TestPointer_Errors(t *testing.T) {
+	tests := []struct {
+		pointer, segment string
+	}{
+		{"foo", "foo"},
+		{"/m~2n", "m~2n"},
+		{"/missing", "missing"},
+		{"/foo/2", "2"},
+		{"/foo/-", "-"},
+		{"/foo/01", "01"},
+		{"/foo/0/bar", "bar"},
+		{"/users/x/email", "x"},
+	}
+
+	doc, err := internal.Parse(pointerDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			_, err := internal.Lookup(doc, tt.pointer)
+
+			var pErr *internal.PointerError
+			if !errors.As(err, &pErr) {
+				t.Fatalf("expected a PointerError, got %v", err)
+			}
+			if pErr.Segment != tt.segment {
+				t.Errorf("expected segment %q, got %q", tt.segment, pErr.Segment)
+			}
+		})
+	}
+}
+
+func TestPointer_String(t *testing.T) {
+	p := internal.Pointer{"a/b", "m~n", "0"}
+	if s := p.String(); s != "/a~1b/m~0n/0" {
+		t.Errorf("got %v", s)
+	}
+
+	parsed, err := internal.ParsePointer(p.String())
+	if err != nil || len(parsed) != 3 || parsed[0] != "a/b" || parsed[1] != "m~n" {
+		t.Errorf("expected %v, got %v (%v)", p, parsed, err)
+	}
+}
@@ -0,0 +1,603 @@
+package internal
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// SchemaError holds the error for when a schema can't be compiled
+type SchemaError struct {
+	msg string
+	// Path points to the part of the schema that is invalid
+	Path Pointer
+}
+
+func (s *SchemaError) Error() string {
+	return fmt.Sprintf("%s at %q in the schema", s.msg, s.Path.String())
+}
+
+// ValidationError is a place where an instance doesn't match its schema.
+type ValidationError struct {
+	Msg string
+	// InstancePath points to the value that failed in the instance
+	InstancePath Pointer
+	// SchemaPath points to the keyword that failed in the schema
+	SchemaPath Pointer
+	// Pos is where the value that failed starts in the source of the instance
+	Pos Position
+}
+
+func (v ValidationError) Error() string {
+	return fmt.Sprintf(
+		"%v: %q %s (schema %q)",
+		v.Pos,
+		v.InstancePath.String(),
+		v.Msg,
+		v.SchemaPath.String(),
+	)
+}
+
+// Schema is a compiled JSON Schema (draft 2020-12). It supports the type,
+// applicator and validation keywords, $ref inside of the same schema, $defs
+// and $anchor. Annotations like format and the unevaluated keywords are ignored.
+// Patterns are Go RE2 regular expressions rather than ECMA-262 ones, so
+// lookarounds and backreferences are rejected. A Schema can be used from more
+// than one goroutine.
+type Schema struct {
+	root    *Node
+	ids     map[string]*Node
+	anchors map[string]*Node
+
+	mu sync.Mutex
+	// patterns caches the compiled patterns, nil for ones that don't compile.
+	// Patterns under keywords collect doesn't walk, like the definitions of
+	// older drafts, are compiled the first time a $ref reaches them.
+	patterns map[string]*regexp.Regexp
+}
+
+// CompileSchema checks the schema document and resolves its references.
+func CompileSchema(doc *Node) (*Schema, error) {
+	s := &Schema{
+		root:     doc,
+		ids:      map[string]*Node{},
+		anchors:  map[string]*Node{},
+		patterns: map[string]*regexp.Regexp{},
+	}
+
+	var refs []Pointer
+	if err := s.collect(doc, Pointer{}, &refs); err != nil {
+		return nil, err
+	}
+
+	for _, p := range refs {
+		n, _ := p.Evaluate(doc)
+		if _, err := s.resolve(n.Lookup("$ref").text); err != nil {
+			return nil, &SchemaError{err.Error(), p.Append("$ref")}
+		}
+	}
+	return s, nil
+}
+
+// collect walks every subschema, it records ids, anchors and refs and compiles the patterns.
+func (s *Schema) collect(n *Node, path Pointer, refs *[]Pointer) error {
+	if n.Kind == BoolKind {
+		return nil
+	}
+	if n.Kind != ObjectKind {
+		return &SchemaError{"Schema should be an object or a boolean", path}
+	}
+
+	for _, m := range n.Members {
+		p := path.Append(m.Name)
+		v := m.Value
+
+		switch m.Name {
+		case "$id":
+			if v.Kind != StringKind {
+				return &SchemaError{"$id should be a string", p}
+			}
+			s.ids[strings.TrimSuffix(v.text, "#")] = n
+		case "$anchor":
+			if v.Kind != StringKind {
+				return &SchemaError{"$anchor should be a string", p}
+			}
+			s.anchors[v.text] = n
+		case "$ref":
+			if v.Kind != StringKind {
+				return &SchemaError{"$ref should be a string", p}
+			}
+			*refs = append(*refs, path)
+		case "pattern":
+			if v.Kind != StringKind {
+				return &SchemaError{"pattern should be a string", p}
+			}
+			if err := s.compilePattern(v.text, p); err != nil {
+				return err
+			}
+		case "type":
+			if err := checkTypeKeyword(v, p); err != nil {
+				return err
+			}
+		case "required":
+			if v.Kind != ArrayKind {
+				return &SchemaError{"required should be an array of strings", p}
+			}
+		case "enum":
+			if v.Kind != ArrayKind {
+				return &SchemaError{"enum should be an array", p}
+			}
+		case "multipleOf", "maximum", "exclusiveMaximum", "minimum", "exclusiveMinimum":
+			if v.Kind != NumberKind {
+				return &SchemaError{m.Name + " should be a number", p}
+			}
+			if m.Name == "multipleOf" && (v.Token.Literal[0] == '-' || isZero(v)) {
+				return &SchemaError{"multipleOf should be bigger than 0", p}
+			}
+		case "maxLength", "minLength", "maxItems", "minItems", "maxProperties", "minProperties",
+			"maxContains", "minContains":
+			if _, err := v.Token.Uint64(); v.Kind != NumberKind || err != nil {
+				return &SchemaError{m.Name + " should be a non-negative integer", p}
+			}
+		case "items", "additionalProperties", "propertyNames", "not", "if", "then", "else",
+			"contains":
+			if err := s.collect(v, p, refs); err != nil {
+				return err
+			}
+		case "prefixItems", "allOf", "anyOf", "oneOf":
+			if v.Kind != ArrayKind || len(v.Elements) == 0 {
+				return &SchemaError{m.Name + " should be a non-empty array of schemas", p}
+			}
+			for i, e := range v.Elements {
+				if err := s.collect(e, p.Append(strconv.Itoa(i)), refs); err != nil {
+					return err
+				}
+			}
+		case "properties", "patternProperties", "$defs", "dependentSchemas":
+			if v.Kind != ObjectKind {
+				return &SchemaError{m.Name + " should be an object of schemas", p}
+			}
+			for _, sub := range v.Members {
+				if m.Name == "patternProperties" {
+					if err := s.compilePattern(sub.Name, p.Append(sub.Name)); err != nil {
+						return err
+					}
+				}
+				if err := s.collect(sub.Value, p.Append(sub.Name), refs); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) compilePattern(pattern string, path Pointer) error {
+	if s.pattern(pattern) == nil {
+		return &SchemaError{fmt.Sprintf("Invalid pattern %q", pattern), path}
+	}
+	return nil
+}
+
+// pattern returns the compiled pattern, it is nil when the pattern is invalid.
+func (s *Schema) pattern(pattern string) *regexp.Regexp {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	re, ok := s.patterns[pattern]
+	if !ok {
+		re, _ = regexp.Compile(pattern)
+		s.patterns[pattern] = re
+	}
+	return re
+}
+
+// matches reports whether the pattern matches the text, an invalid pattern
+// matches nothing.
+func (s *Schema) matches(pattern, text string) bool {
+	re := s.pattern(pattern)
+	return re != nil && re.MatchString(text)
+}
+
+func checkTypeKeyword(v *Node, path Pointer) error {
+	types := []*Node{v}
+	if v.Kind == ArrayKind {
+		types = v.Elements
+	}
+
+	for _, t := range types {
+		if t.Kind != StringKind {
+			return &SchemaError{"type should be a string or an array of strings", path}
+		}
+		switch t.text {
+		case "null", "boolean", "object", "array", "number", "string", "integer":
+		default:
+			return &SchemaError{fmt.Sprintf("Unknown type %q", t.text), path}
+		}
+	}
+	return nil
+}
+
+// resolve finds the subschema a $ref points to, only references into the
+// same schema are supported.
+func (s *Schema) resolve(ref string) (*Node, error) {
+	base, fragment, _ := strings.Cut(ref, "#")
+
+	n := s.root
+	if base != "" {
+		var ok bool
+		if n, ok = s.ids[base]; !ok {
+			return nil, fmt.Errorf("Can't resolve %q, only references inside of the schema are supported", ref)
+		}
+	}
+
+	if fragment == "" {
+		return n, nil
+	}
+	if !strings.HasPrefix(fragment, "/") {
+		if a, ok := s.anchors[fragment]; ok {
+			return a, nil
+		}
+		return nil, fmt.Errorf("Unknown anchor %q", fragment)
+	}
+	return Lookup(n, "#"+fragment)
+}
+
+// Validate checks the instance against the schema and returns every error.
+func (s *Schema) Validate(instance *Node) []ValidationError {
+	v := schemaValidator{s: s, active: map[[2]*Node]bool{}}
+	return v.validate(s.root, instance, Pointer{}, Pointer{})
+}
+
+type schemaValidator struct {
+	s *Schema
+	// active holds the schema and instance pairs that are being validated, so
+	// recursive references stop instead of looping forever
+	active map[[2]*Node]bool
+}
+
+func (v *schemaValidator) validate(schema, inst *Node, ipath, spath Pointer) []ValidationError {
+	if schema.Kind == BoolKind {
+		if schema.Bool() {
+			return nil
+		}
+		return []ValidationError{{"is not allowed", ipath, spath, inst.Token.Pos}}
+	}
+
+	key := [2]*Node{schema, inst}
+	if v.active[key] {
+		return nil
+	}
+	v.active[key] = true
+	defer delete(v.active, key)
+
+	var errs []ValidationError
+	fail := func(keyword, format string, args ...any) {
+		errs = append(errs, ValidationError{
+			fmt.Sprintf(format, args...),
+			ipath,
+			spath.Append(keyword),
+			inst.Token.Pos,
+		})
+	}
+
+	for _, m := range schema.Members {
+		kw, k := m.Name, m.Value
+		switch kw {
+		case "$ref":
+			target, err := v.s.resolve(k.text)
+			if err == nil {
+				errs = append(errs, v.validate(target, inst, ipath, spath.Append(kw))...)
+			}
+		case "type":
+			if !matchesType(k, inst) {
+				fail(kw, "should be of type %v, instead got %s", k, inst.Kind)
+			}
+		case "enum":
+			found := false
+			for _, e := range k.Elements {
+				found = found || e.Equal(inst)
+			}
+			if !found {
+				fail(kw, "should be one of %v", k)
+			}
+		case "const":
+			if !k.Equal(inst) {
+				fail(kw, "should be %v", k)
+			}
+		case "allOf":
+			for i, sub := range k.Elements {
+				errs = append(errs, v.validate(sub, inst, ipath, spath.Append(kw, strconv.Itoa(i)))...)
+			}
+		case "anyOf", "oneOf":
+			matches := 0
+			for i, sub := range k.Elements {
+				if len(v.validate(sub, inst, ipath, spath.Append(kw, strconv.Itoa(i)))) == 0 {
+					matches++
+				}
+			}
+			if matches == 0 {
+				fail(kw, "should match at least one schema of %s", kw)
+			} else if kw == "oneOf" && matches > 1 {
+				fail(kw, "should match exactly one schema of oneOf, matched %d", matches)
+			}
+		case "not":
+			if len(v.validate(k, inst, ipath, spath.Append(kw))) == 0 {
+				fail(kw, "should not match the schema of not")
+			}
+		case "if":
+			branch := "else"
+			if len(v.validate(k, inst, ipath, spath.Append(kw))) == 0 {
+				branch = "then"
+			}
+			if sub := schema.Lookup(branch); sub != nil {
+				errs = append(errs, v.validate(sub, inst, ipath, spath.Append(branch))...)
+			}
+		}
+
+		switch inst.Kind {
+		case NumberKind:
+			errs = append(errs, v.validateNumber(kw, k, inst, ipath, spath)...)
+		case StringKind:
+			errs = append(errs, v.validateString(kw, k, inst, ipath, spath)...)
+		case ArrayKind:
+			errs = append(errs, v.validateArray(schema, kw, k, inst, ipath, spath)...)
+		case ObjectKind:
+			errs = append(errs, v.validateObject(schema, kw, k, inst, ipath, spath)...)
+		}
+	}
+	return errs
+}
+
+func matchesType(t, inst *Node) bool {
+	types := []*Node{t}
+	if t.Kind == ArrayKind {
+		types = t.Elements
+	}
+
+	for _, t := range types {
+		switch t.text {
+		case "integer":
+			if d, err := inst.Token.Decimal(); inst.Kind == NumberKind && err == nil && d.IsInteger() {
+				return true
+			}
+		case string(inst.Kind):
+			return true
+		}
+	}
+	return false
+}
+
+func (v *schemaValidator) validateNumber(kw string, k, inst *Node, ipath, spath Pointer) []ValidationError {
+	c := compareNumbers(inst.Token, k.Token)
+	msg := ""
+	switch kw {
+	case "maximum":
+		if c > 0 {
+			msg = "should be at most " + k.Token.Literal
+		}
+	case "exclusiveMaximum":
+		if c >= 0 {
+			msg = "should be less than " + k.Token.Literal
+		}
+	case "minimum":
+		if c < 0 {
+			msg = "should be at least " + k.Token.Literal
+		}
+	case "exclusiveMinimum":
+		if c <= 0 {
+			msg = "should be bigger than " + k.Token.Literal
+		}
+	case "multipleOf":
+		if !isMultipleOf(inst, k) {
+			msg = "should be a multiple of " + k.Token.Literal
+		}
+	}
+
+	if msg == "" {
+		return nil
+	}
+	return []ValidationError{{msg, ipath, spath.Append(kw), inst.Token.Pos}}
+}
+
+func isMultipleOf(inst, k *Node) bool {
+	a, errA := inst.Token.Decimal()
+	b, errB := k.Token.Decimal()
+	if errA != nil || errB != nil || b.Coefficient.Sign() == 0 {
+		return false
+	}
+	if a.Coefficient.Sign() == 0 {
+		return true
+	}
+	// a/b is (a.Coefficient/b.Coefficient) * 10^exp. 10^-exp can't divide a
+	// quotient with fewer digits, and past the bits of b.Coefficient a bigger
+	// power of ten can't cancel more of its factors, so exp stays as short as
+	// the literals.
+	exp := a.Exponent - b.Exponent
+	if -exp > len(a.Coefficient.String()) {
+		return false
+	}
+	exp = min(exp, b.Coefficient.BitLen())
+	return scale(new(big.Rat).SetFrac(a.Coefficient, b.Coefficient), exp).IsInt()
+}
+
+func isZero(n *Node) bool {
+	d, err := n.Token.Decimal()
+	return err == nil && d.Coefficient.Sign() == 0
+}
+
+func (v *schemaValidator) validateString(kw string, k, inst *Node, ipath, spath Pointer) []ValidationError {
+	msg := ""
+	switch kw {
+	case "maxLength":
+		if n, _ := k.Token.Uint64(); uint64(utf8.RuneCountInString(inst.text)) > n {
+			msg = fmt.Sprintf("should be at most %d characters long", n)
+		}
+	case "minLength":
+		if n, _ := k.Token.Uint64(); uint64(utf8.RuneCountInString(inst.text)) < n {
+			msg = fmt.Sprintf("should be at least %d characters long", n)
+		}
+	case "pattern":
+		if !v.s.matches(k.text, inst.text) {
+			msg = fmt.Sprintf("should match the pattern %q", k.text)
+		}
+	}
+
+	if msg == "" {
+		return nil
+	}
+	return []ValidationError{{msg, ipath, spath.Append(kw), inst.Token.Pos}}
+}
+
+func (v *schemaValidator) validateArray(schema *Node, kw string, k, inst *Node, ipath, spath Pointer) []ValidationError {
+	var errs []ValidationError
+	fail := func(format string, args ...any) {
+		errs = append(errs, ValidationError{fmt.Sprintf(format, args...), ipath, spath.Append(kw), inst.Token.Pos})
+	}
+
+	elements := inst.Elements
+	switch kw {
+	case "maxItems":
+		if n, _ := k.Token.Uint64(); uint64(len(elements)) > n {
+			fail("should have at most %d items", n)
+		}
+	case "minItems":
+		if n, _ := k.Token.Uint64(); uint64(len(elements)) < n {
+			fail("should have at least %d items", n)
+		}
+	case "uniqueItems":
+		if !k.Bool() {
+			break
+		}
+		for i := range elements {
+			for j := i + 1; j < len(elements); j++ {
+				if elements[i].Equal(elements[j]) {
+					fail("should have unique items, %d and %d are equal", i, j)
+					return errs
+				}
+			}
+		}
+	case "prefixItems":
+		for i, sub := range k.Elements {
+			if i >= len(elements) {
+				break
+			}
+			errs = append(errs, v.validate(sub, elements[i], ipath.Append(strconv.Itoa(i)), spath.Append(kw, strconv.Itoa(i)))...)
+		}
+	case "items":
+		start := 0
+		if prefix := schema.Lookup("prefixItems"); prefix != nil {
+			start = len(prefix.Elements)
+		}
+		for i := start; i < len(elements); i++ {
+			errs = append(errs, v.validate(k, elements[i], ipath.Append(strconv.Itoa(i)), spath.Append(kw))...)
+		}
+	case "contains":
+		matches := uint64(0)
+		for i, e := range elements {
+			if len(v.validate(k, e, ipath.Append(strconv.Itoa(i)), spath.Append(kw))) == 0 {
+				matches++
+			}
+		}
+
+		minimum, maximum := uint64(1), uint64(len(elements))
+		if n := schema.Lookup("minContains"); n != nil {
+			minimum, _ = n.Token.Uint64()
+		}
+		if n := schema.Lookup("maxContains"); n != nil {
+			maximum, _ = n.Token.Uint64()
+		}
+		if matches < minimum {
+			fail("should contain at least %d matching items, found %d", minimum, matches)
+		} else if matches > maximum {
+			fail("should contain at most %d matching items, found %d", maximum, matches)
+		}
+	}
+	return errs
+}
+
+func (v *schemaValidator) validateObject(schema *Node, kw string, k, inst *Node, ipath, spath Pointer) []ValidationError {
+	var errs []ValidationError
+	fail := func(format string, args ...any) {
+		errs = append(errs, ValidationError{fmt.Sprintf(format, args...), ipath, spath.Append(kw), inst.Token.Pos})
+	}
+
+	switch kw {
+	case "maxProperties":
+		if n, _ := k.Token.Uint64(); uint64(len(inst.Members)) > n {
+			fail("should have at most %d properties", n)
+		}
+	case "minProperties":
+		if n, _ := k.Token.Uint64(); uint64(len(inst.Members)) < n {
+			fail("should have at least %d properties", n)
+		}
+	case "required":
+		for _, r := range k.Elements {
+			if r.Kind == StringKind && inst.Lookup(r.text) == nil {
+				fail("is missing the required property %q", r.text)
+			}
+		}
+	case "dependentRequired":
+		for _, dep := range k.Members {
+			if inst.Lookup(dep.Name) == nil {
+				continue
+			}
+			for _, r := range dep.Value.Elements {
+				if inst.Lookup(r.text) == nil {
+					fail("is missing the property %q, which %q requires", r.text, dep.Name)
+				}
+			}
+		}
+	case "dependentSchemas":
+		for _, dep := range k.Members {
+			if inst.Lookup(dep.Name) != nil {
+				errs = append(errs, v.validate(dep.Value, inst, ipath, spath.Append(kw, dep.Name))...)
+			}
+		}
+	case "propertyNames":
+		for _, m := range inst.Members {
+			name := NewString(m.Name)
+			name.Token.Pos = m.Key.Pos
+			errs = append(errs, v.validate(k, name, ipath.Append(m.Name), spath.Append(kw))...)
+		}
+	case "properties":
+		for _, m := range inst.Members {
+			if sub := k.Lookup(m.Name); sub != nil {
+				errs = append(errs, v.validate(sub, m.Value, ipath.Append(m.Name), spath.Append(kw, m.Name))...)
+			}
+		}
+	case "patternProperties":
+		for _, m := range inst.Members {
+			for _, p := range k.Members {
+				if v.s.matches(p.Name, m.Name) {
+					errs = append(errs, v.validate(p.Value, m.Value, ipath.Append(m.Name), spath.Append(kw, p.Name))...)
+				}
+			}
+		}
+	case "additionalProperties":
+		properties, patterns := schema.Lookup("properties"), schema.Lookup("patternProperties")
+		for _, m := range inst.Members {
+			if properties != nil && properties.Lookup(m.Name) != nil {
+				continue
+			}
+			if patterns != nil && v.matchesAnyPattern(patterns, m.Name) {
+				continue
+			}
+			errs = append(errs, v.validate(k, m.Value, ipath.Append(m.Name), spath.Append(kw))...)
+		}
+	}
+	return errs
+}
+
+func (v *schemaValidator) matchesAnyPattern(patterns *Node, name string) bool {
+	for _, p := range patterns.Members {
+		if v.s.matches(p.Name, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package internal_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	tests := []struct {
+		name, schema, instance string
+		expected               []string
+	}{
+		{"True schema", `true`, `{"a": 1}`, nil},
+		{"False schema", `false`, `1`, []string{`1:1: "" is not allowed (schema "")`}},
+		{"Type", `{"type": "string"}`, `1`, []string{`1:1: "" should be of type "string", instead got number (schema "/type")`}},
+		{"Type list", `{"type": ["string", "null"]}`, `null`, nil},
+		{"Integer", `{"type": "integer"}`, `[1.0]`, []string{`1:1: "" should be of type "integer", instead got array (schema "/type")`}},
+		{"Integer accepts 1.0", `{"items": {"type": "integer"}}`, `[1.0, 1e2]`, nil},
+		{"Integer with a tiny exponent", `{"type": "integer"}`, `1e-100000000`,
+			[]string{`1:1: "" should be of type "integer", instead got number (schema "/type")`}},
+		{"Multiple with huge exponents", `{"items": {"multipleOf": 1e-100000000}}`, `[1e-99999999, 3e-100000001, 1e100000000]`,
+			[]string{`1:15: "/1" should be a multiple of 1e-100000000 (schema "/items/multipleOf")`}},
+		{"Enum and const", `{"properties": {"a": {"enum": [1, "x"]}, "b": {"const": {"k": [true]}}}}`,
+			`{"a": "x", "b": {"k": [true]}}`, nil},
+		{"Enum fails", `{"enum": [1, "x"]}`, `"y"`, []string{`1:1: "" should be one of [1,"x"] (schema "/enum")`}},
+		{"Numeric", `{"minimum": 1, "exclusiveMaximum": 10, "multipleOf": 0.5}`, `[0.5, 10, 2.5]`, nil},
+		{"Numeric fails", `{"items": {"minimum": 1, "exclusiveMaximum": 10, "multipleOf": 0.5}}`, `[0.5, 10, 2.25]`, []string{
+			`1:2: "/0" should be at least 1 (schema "/items/minimum")`,
+			`1:7: "/1" should be less than 10 (schema "/items/exclusiveMaximum")`,
+			`1:11: "/2" should be a multiple of 0.5 (schema "/items/multipleOf")`,
+		}},
+		{"String", `{"minLength": 2, "maxLength": 3, "pattern": "^[a-zé]+$"}`, `"éé"`, nil},
+		{"String fails", `{"items": {"minLength": 2, "pattern": "^a"}}`, `["b", "aa"]`, []string{
+			`1:2: "/0" should be at least 2 characters long (schema "/items/minLength")`,
+			`1:2: "/0" should match the pattern "^a" (schema "/items/pattern")`,
+		}},
+		{"Object", `{
+			"type": "object",
+			"properties": {"name": {"type": "string"}, "age": {"type": "integer", "minimum": 0}},
+			"required": ["name", "email"],
+			"additionalProperties": false
+		}`, "{\n  \"name\": 1,\n  \"age\": -1,\n  \"extra\": true\n}", []string{
+			`2:11: "/name" should be of type "string", instead got number (schema "/properties/name/type")`,
+			`3:10: "/age" should be at least 0 (schema "/properties/age/minimum")`,
+			`1:1: "" is missing the required property "email" (schema "/required")`,
+			`4:12: "/extra" is not allowed (schema "/additionalProperties")`,
+		}},
+		{"Pattern under an unknown keyword", `{"$ref": "#/definitions/a", "definitions": {"a": {"pattern": "x"}}}`, `"y"`,
+			[]string{`1:1: "" should match the pattern "x" (schema "/$ref/pattern")`}},
+		{"Pattern properties under an unknown keyword",
+			`{"$ref": "#/definitions/a", "definitions": {"a": {"patternProperties": {"^x-": false}, "additionalProperties": false}}}`,
+			`{"x-a": 1}`, []string{`1:9: "/x-a" is not allowed (schema "/$ref/patternProperties/^x-")`}},
+		{"Pattern properties", `{"patternProperties": {"^x-": {"type": "string"}}, "additionalProperties": {"type": "number"}}`,
+			`{"x-a": "s", "b": 1}`, nil},
+		{"Array", `{"prefixItems": [{"type": "string"}], "items": {"type": "number"}, "minItems": 2, "uniqueItems": true}`,
+			`["a", 1, 2]`, nil},
+		{"Array fails", `{"prefixItems": [{"type": "string"}], "items": false, "uniqueItems": true, "contains": {"const": 5}}`,
+			`[1, 1]`, []string{
+				`1:2: "/0" should be of type "string", instead got number (schema "/prefixItems/0/type")`,
+				`1:5: "/1" is not allowed (schema "/items")`,
+				`1:1: "" should have unique items, 0 and 1 are equal (schema "/uniqueItems")`,
+				`1:1: "" should contain at least 1 matching items, found 0 (schema "/contains")`,
+			}},
+		{"Combinators", `{"allOf": [{"type": "number"}], "anyOf": [{"minimum": 5}, {"maximum": 0}], "oneOf": [{"multipleOf": 2}, {"multipleOf": 3}], "not": {"const": 8}}`,
+			`9`, nil},
+		{"Combinators fail", `{"anyOf": [{"minimum": 5}, {"maximum": 0}], "oneOf": [{"multipleOf": 2}, {"multipleOf": 3}], "not": {"const": 6}}`,
+			`6`, []string{
+				`1:1: "" should match exactly one schema of oneOf, matched 2 (schema "/oneOf")`,
+				`1:1: "" should not match the schema of not (schema "/not")`,
+			}},
+		{"If then else", `{"if": {"type": "string"}, "then": {"minLength": 2}, "else": {"type": "number"}}`,
+			`true`, []string{`1:1: "" should be of type "number", instead got boolean (schema "/else/type")`}},
+		{"Ref", `{"$defs": {"pos": {"type": "integer", "minimum": 1}}, "properties": {"a": {"$ref": "#/$defs/pos"}}}`,
+			`{"a": 0}`, []string{`1:7: "/a" should be at least 1 (schema "/properties/a/$ref/minimum")`}},
+		{"Anchor", `{"$defs": {"s": {"$anchor": "str", "type": "string"}}, "items": {"$ref": "#str"}}`, `["a", "b"]`, nil},
+		{"Recursive ref", `{"type": "object", "properties": {"child": {"$ref": "#"}}, "required": ["id"]}`,
+			`{"id": 1, "child": {"child": {"id": 3}}}`, []string{
+				`1:20: "/child" is missing the required property "id" (schema "/properties/child/$ref/required")`,
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := internal.Parse(tt.schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, err := internal.CompileSchema(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			inst, err := internal.Parse(tt.instance)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var actual []string
+			for _, e := range s.Validate(inst) {
+				actual = append(actual, e.Error())
+			}
+			if !slices.Equal(actual, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCompileSchema_Invalid(t *testing.T) {
+	tests := []struct {
+		name, schema string
+	}{
+		{"Not a schema", `1`},
+		{"Unknown type", `{"type": "float"}`},
+		{"Bad pattern", `{"pattern": "(["}`},
+		{"Negative minLength", `{"minLength": -1}`},
+		{"Zero multipleOf", `{"multipleOf": 0}`},
+		{"Missing ref", `{"$ref": "#/$defs/missing"}`},
+		{"External ref", `{"$ref": "https://example.com/schema.json"}`},
+		{"Nested invalid", `{"properties": {"a": {"items": 1}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := internal.Parse(tt.schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := internal.CompileSchema(doc); err == nil {
+				t.Errorf("expected an error for %s", tt.schema)
+			}
+		})
+	}
+}
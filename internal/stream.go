@@ -0,0 +1,272 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// recordSeparator starts every record of a JSON text sequence
+const recordSeparator = '\x1e'
+
+// StreamError holds the error for when a record doesn't fit the stream format
+type StreamError struct {
+	// Code names the kind of error, like CodeTruncatedValue
+	Code string
+	msg  string
+	Pos  Position
+}
+
+func (s *StreamError) Error() string {
+	return fmt.Sprintf("%s at %v", s.msg, s.Pos)
+}
+
+// StreamReader reads a stream of JSON documents one record at a time, like
+// NDJSONReader a record that isn't valid only sets its Err and reading goes
+// on with the next one.
+type StreamReader struct {
+	r    *bufio.Reader
+	opts ParseOptions
+	seq  bool
+	// pos is the position of the next byte
+	pos Position
+	// separated is set once a record separator was read
+	separated bool
+	rec       Record
+	err       error
+	done      bool
+}
+
+// NewSequenceReader creates a reader for an RFC 7464 JSON text sequence,
+// where every record starts with an RS character. A malformed record is
+// skipped up to the next RS, and a top-level number, true, false or null
+// that isn't followed by whitespace is reported as possibly truncated.
+func NewSequenceReader(r io.Reader, opts ParseOptions) *StreamReader {
+	return &StreamReader{r: bufio.NewReader(r), opts: opts, seq: true, pos: Position{Line: 1, Column: 1}}
+}
+
+// NewConcatReader creates a reader for documents written one after another,
+// like {"a":1}{"a":2} or 1 2, with optional whitespace between them. A record
+// ends where its brackets balance, so an unexpected } or ] ends the record it
+// is in and a stray one is a record of its own. A record that is never
+// closed runs to the end of the input.
+func NewConcatReader(r io.Reader, opts ParseOptions) *StreamReader {
+	return &StreamReader{r: bufio.NewReader(r), opts: opts, pos: Position{Line: 1, Column: 1}}
+}
+
+// Next reads the next record, it returns false at the end of the input or
+// when reading fails.
+func (s *StreamReader) Next() bool {
+	if s.seq {
+		return s.nextSequence()
+	}
+	return s.nextConcat()
+}
+
+// Record returns the record read by the last call to Next.
+func (s *StreamReader) Record() Record {
+	return s.rec
+}
+
+// Err returns the error that stopped reading, it is nil at the end of the
+// input. Records that aren't valid don't stop reading.
+func (s *StreamReader) Err() error {
+	return s.err
+}
+
+func (s *StreamReader) nextSequence() bool {
+	for !s.done {
+		text, err := s.r.ReadString(recordSeparator)
+		if err != nil && err != io.EOF {
+			s.err = err
+			return false
+		}
+		s.done = err == io.EOF
+
+		start := s.pos
+		separated := s.separated
+		for i := 0; i < len(text); i++ {
+			s.advance(text[i])
+		}
+		if strings.HasSuffix(text, string(recordSeparator)) {
+			text = text[:len(text)-1]
+			s.separated = true
+		}
+		// several RS in a row don't make empty records
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		s.rec = parseRecord(text, s.opts, start)
+		var recErr error
+		switch {
+		case !separated:
+			recErr = &StreamError{CodeMissingRecordSeparator, "Expected a record separator before the record", start}
+		case s.rec.Err == nil && s.rec.Doc.Kind != ObjectKind && s.rec.Doc.Kind != ArrayKind &&
+			s.rec.Doc.Kind != StringKind && !isSpace(text[len(text)-1]):
+			recErr = &StreamError{CodeTruncatedValue, "Possibly truncated value", s.rec.Doc.Token.Pos}
+		}
+		if recErr != nil {
+			s.rec.Doc, s.rec.Err = nil, recErr
+			s.rec.Diagnostics = []Diagnostic{Diagnose(recErr, nil)}
+		}
+		return true
+	}
+	return false
+}
+
+func (s *StreamReader) nextConcat() bool {
+	if !s.skipSpace() {
+		return false
+	}
+
+	start := s.pos
+	var b strings.Builder
+	s.scanValue(&b)
+	if s.err != nil {
+		return false
+	}
+	s.rec = parseRecord(b.String(), s.opts, start)
+	return true
+}
+
+// skipSpace moves past whitespace, and comments when they are allowed, it
+// returns false when the input ends.
+func (s *StreamReader) skipSpace() bool {
+	for {
+		c, ok := s.peek()
+		switch {
+		case !ok:
+			return false
+		case isSpace(c):
+			s.read(nil)
+		case c == '/' && s.opts.comments():
+			s.read(nil)
+			s.scanComment(nil)
+		default:
+			return true
+		}
+	}
+}
+
+// scanValue reads the text of the next value, the bytes read are written to b.
+func (s *StreamReader) scanValue(b *strings.Builder) {
+	c, _ := s.read(b)
+	switch c {
+	case '{', '[':
+		closing := []byte{closingFor(c)}
+		for len(closing) > 0 {
+			c, ok := s.read(b)
+			if !ok {
+				return
+			}
+			switch {
+			case c == '"' || (c == '\'' && s.opts.json5()):
+				s.scanString(b, c)
+			case c == '/' && s.opts.comments():
+				s.scanComment(b)
+			case c == '{' || c == '[':
+				closing = append(closing, closingFor(c))
+			case c == '}' || c == ']':
+				if c != closing[len(closing)-1] {
+					return
+				}
+				closing = closing[:len(closing)-1]
+			}
+		}
+	case '"', '\'':
+		s.scanString(b, c)
+	case '}', ']', ',', ':':
+		// a stray token is a record of its own
+	default:
+		for {
+			c, ok := s.peek()
+			if !ok || isSpace(c) || strings.IndexByte(`{}[],:"'/`, c) >= 0 {
+				return
+			}
+			s.read(b)
+		}
+	}
+}
+
+// scanString reads up to the quote that closes the string, a line break ends
+// it early since strings can't hold one.
+func (s *StreamReader) scanString(b *strings.Builder, quote byte) {
+	for {
+		c, ok := s.read(b)
+		if !ok || c == quote || c == '\n' {
+			return
+		}
+		if c == '\\' {
+			s.read(b)
+		}
+	}
+}
+
+// scanComment reads the rest of a comment after its slash.
+func (s *StreamReader) scanComment(b *strings.Builder) {
+	kind, ok := s.peek()
+	if !ok || (kind != '/' && kind != '*') {
+		return
+	}
+	s.read(b)
+
+	prev := byte(0)
+	for {
+		c, ok := s.read(b)
+		if !ok || (kind == '/' && c == '\n') || (kind == '*' && prev == '*' && c == '/') {
+			return
+		}
+		prev = c
+	}
+}
+
+// read reads the next byte, writing it to b when b isn't nil.
+func (s *StreamReader) read(b *strings.Builder) (byte, bool) {
+	c, err := s.r.ReadByte()
+	if err != nil {
+		if err != io.EOF {
+			s.err = err
+		}
+		return 0, false
+	}
+	s.advance(c)
+	if b != nil {
+		b.WriteByte(c)
+	}
+	return c, true
+}
+
+func (s *StreamReader) peek() (byte, bool) {
+	c, err := s.r.Peek(1)
+	if err != nil {
+		if err != io.EOF {
+			s.err = err
+		}
+		return 0, false
+	}
+	return c[0], true
+}
+
+// advance moves the position past the byte.
+func (s *StreamReader) advance(c byte) {
+	s.pos.Offset++
+	if c == '\n' {
+		s.pos.Line++
+		s.pos.Column = 1
+	} else {
+		s.pos.Column++
+	}
+}
+
+func closingFor(c byte) byte {
+	if c == '{' {
+		return '}'
+	}
+	return ']'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
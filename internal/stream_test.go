@@ -0,0 +1,153 @@
+package internal_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KylerWilson01/json-parser/internal"
+)
+
+type streamRecord struct {
+	text, err string
+}
+
+func readStream(t *testing.T, r *internal.StreamReader) []streamRecord {
+	t.Helper()
+	var records []streamRecord
+	for r.Next() {
+		rec := r.Record()
+		actual := streamRecord{text: rec.Text}
+		if rec.Err != nil {
+			actual.err = rec.Err.Error()
+		} else if rec.Doc == nil {
+			t.Errorf("expected a document for %q", rec.Text)
+		}
+		records = append(records, actual)
+	}
+	if r.Err() != nil {
+		t.Fatal(r.Err())
+	}
+	return records
+}
+
+func TestSequenceReader(t *testing.T) {
+	tests := []struct {
+		name, input string
+		expected    []streamRecord
+	}{
+		{
+			"Records",
+			"\x1e{\"a\": 1}\n\x1e[true]\n\x1e\x1e\"s\"\n",
+			[]streamRecord{{"{\"a\": 1}\n", ""}, {"[true]\n", ""}, {"\"s\"\n", ""}},
+		},
+		{
+			"Resync after a malformed record",
+			"\x1e{\"a\": \n\x1e{\"b\": 2}\n",
+			[]streamRecord{
+				{"{\"a\": \n", "Length of the state should be 0. Instead got 1 at 2:1"},
+				{"{\"b\": 2}\n", ""},
+			},
+		},
+		{
+			"Resync after more than one value",
+			"\x1e{}\n\x1e\"a\" \"b\"\n\x1e[]\n",
+			[]streamRecord{
+				{"{}\n", ""},
+				{"\"a\" \"b\"\n", `Expected the end of the document at 2:6, got: "b"`},
+				{"[]\n", ""},
+			},
+		},
+		{
+			"Truncated number",
+			"\x1e123\x1e12\n\x1etrue",
+			[]streamRecord{
+				{"123", "Possibly truncated value at 1:2"},
+				{"12\n", ""},
+				{"true", "Possibly truncated value at 2:2"},
+			},
+		},
+		{
+			"Missing separator",
+			"{}\n\x1e{}\n",
+			[]streamRecord{{"{}\n", "Expected a record separator before the record at 1:1"}, {"{}\n", ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := internal.NewSequenceReader(strings.NewReader(tt.input), internal.ParseOptions{})
+			actual := readStream(t, r)
+			if len(actual) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, actual)
+			}
+			for i, e := range tt.expected {
+				if actual[i] != e {
+					t.Errorf("expected %v, got %v", e, actual[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConcatReader(t *testing.T) {
+	tests := []struct {
+		name, input string
+		opts        internal.ParseOptions
+		expected    []streamRecord
+	}{
+		{
+			"Objects", `{"a":"}"}{"b":[1,{}]}` + "\n  [2]",
+			internal.ParseOptions{},
+			[]streamRecord{{`{"a":"}"}`, ""}, {`{"b":[1,{}]}`, ""}, {`[2]`, ""}},
+		},
+		{
+			"Scalars", `1 "two"true null-3.5e1"x"`,
+			internal.ParseOptions{},
+			[]streamRecord{{`1`, ""}, {`"two"`, ""}, {`true`, ""}, {`null-3.5e1`, `Expected the end of the document at 1:17, got: "-3.5e1"`}, {`"x"`, ""}},
+		},
+		{
+			"Stray closing bracket", `{"a":1}}{"b":2}`,
+			internal.ParseOptions{},
+			[]streamRecord{{`{"a":1}`, ""}, {`}`, "Should be inside an object. Instead got Invalid at 1:8"}, {`{"b":2}`, ""}},
+		},
+		{
+			"Mismatched brackets", "{\"a\":[1}\n{\"b\":2}",
+			internal.ParseOptions{},
+			[]streamRecord{{`{"a":[1}`, "Should be inside an object. Instead got InsideArray at 1:8"}, {`{"b":2}`, ""}},
+		},
+		{
+			"Comments", "// first\n{\"a\": 1 /* } */}\n// second\n[2]",
+			internal.JSONC,
+			[]streamRecord{{`{"a": 1 /* } */}`, ""}, {`[2]`, ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := internal.NewConcatReader(strings.NewReader(tt.input), tt.opts)
+			actual := readStream(t, r)
+			if len(actual) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, actual)
+			}
+			for i, e := range tt.expected {
+				if actual[i] != e {
+					t.Errorf("expected %v, got %v", e, actual[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConcatReader_Positions(t *testing.T) {
+	r := internal.NewConcatReader(strings.NewReader("{}\n  {\"a\": 1}"), internal.ParseOptions{})
+	r.Next()
+	r.Next()
+	rec := r.Record()
+	if rec.Line != 2 || rec.Offset != 5 {
+		t.Errorf("expected line 2 offset 5, got line %d offset %d", rec.Line, rec.Offset)
+	}
+	expected := internal.Position{Offset: 11, Line: 2, Column: 9}
+	if pos := rec.Doc.Lookup("a").Token.Pos; pos != expected {
+		t.Errorf("expected %v, got %v", expected, pos)
+	}
+}